@@ -0,0 +1,213 @@
+// Package archive 以追加写的方式记录直播间收到的原始 PushFrame，用于排查解析问题、回放与构造测试数据
+//
+// 文件格式：
+//
+//	magic "DYFA" | version(1 字节) | uvarint(头部长度) | JSON 头部
+//	记录: uvarint(帧长度) | 8 字节大端接收时间(UnixNano) | 原始帧
+package archive
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	magic   = "DYFA"
+	version = 1
+	// maxFrameSize 单帧的最大字节数，超过视为文件损坏
+	maxFrameSize = 64 << 20
+)
+
+// ErrBadArchive 文件不是合法的帧归档
+var ErrBadArchive = errors.New("不是合法的帧归档文件")
+
+// DeviceProfile 录制时使用的设备信息
+type DeviceProfile struct {
+	UserAgent string `json:"user_agent"`
+	DeviceId  string `json:"device_id"`
+}
+
+// Header 归档头部
+type Header struct {
+	RoomId    string        `json:"room_id"`
+	WebRid    string        `json:"web_rid"`
+	Device    DeviceProfile `json:"device"`
+	StartTime time.Time     `json:"start_time"`
+}
+
+// Frame 一条录制的帧
+type Frame struct {
+	Time time.Time
+	Data []byte
+}
+
+// Writer 帧归档写入器，可并发调用
+type Writer struct {
+	mu     sync.Mutex
+	w      *bufio.Writer
+	closer io.Closer
+	frames int64
+}
+
+// Create 创建归档文件并写入头部
+func Create(path string, header Header) (*Writer, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	w, err := NewWriter(f, header)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	w.closer = f
+	return w, nil
+}
+
+// NewWriter 在 w 上写入头部并返回写入器
+func NewWriter(w io.Writer, header Header) (*Writer, error) {
+	if header.StartTime.IsZero() {
+		header.StartTime = time.Now()
+	}
+	data, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	bw := bufio.NewWriter(w)
+	buf := make([]byte, 0, len(magic)+1+binary.MaxVarintLen64+len(data))
+	buf = append(buf, magic...)
+	buf = append(buf, version)
+	buf = binary.AppendUvarint(buf, uint64(len(data)))
+	buf = append(buf, data...)
+	if _, err := bw.Write(buf); err != nil {
+		return nil, err
+	}
+	if err := bw.Flush(); err != nil {
+		return nil, err
+	}
+	return &Writer{w: bw}, nil
+}
+
+// WriteFrame 追加一帧，每帧写入后立即刷新，进程异常退出时最多丢失正在写入的一帧
+func (w *Writer) WriteFrame(at time.Time, data []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var head [binary.MaxVarintLen64 + 8]byte
+	n := binary.PutUvarint(head[:], uint64(len(data)))
+	binary.BigEndian.PutUint64(head[n:], uint64(at.UnixNano()))
+	if _, err := w.w.Write(head[:n+8]); err != nil {
+		return err
+	}
+	if _, err := w.w.Write(data); err != nil {
+		return err
+	}
+	w.frames++
+	return w.w.Flush()
+}
+
+// Frames 已写入的帧数
+func (w *Writer) Frames() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.frames
+}
+
+// Close 刷新缓冲并关闭文件
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := w.w.Flush()
+	if w.closer != nil {
+		if cerr := w.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Reader 帧归档读取器
+type Reader struct {
+	Header Header
+	r      *bufio.Reader
+	closer io.Closer
+}
+
+// Open 打开归档文件并读取头部
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r.closer = f
+	return r, nil
+}
+
+// NewReader 从 r 读取头部并返回读取器
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	head := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(br, head); err != nil {
+		return nil, ErrBadArchive
+	}
+	if string(head[:len(magic)]) != magic {
+		return nil, ErrBadArchive
+	}
+	if head[len(magic)] != version {
+		return nil, fmt.Errorf("不支持的归档版本: %d", head[len(magic)])
+	}
+	size, err := binary.ReadUvarint(br)
+	if err != nil || size > maxFrameSize {
+		return nil, ErrBadArchive
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(br, data); err != nil {
+		return nil, ErrBadArchive
+	}
+	reader := &Reader{r: br}
+	if err := json.Unmarshal(data, &reader.Header); err != nil {
+		return nil, fmt.Errorf("解析归档头部失败: %w", err)
+	}
+	return reader, nil
+}
+
+// Next 读取下一帧，读完返回 io.EOF，最后一帧不完整时返回 io.ErrUnexpectedEOF
+func (r *Reader) Next() (*Frame, error) {
+	size, err := binary.ReadUvarint(r.r)
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if size > maxFrameSize {
+		return nil, ErrBadArchive
+	}
+	buf := make([]byte, 8+size)
+	if _, err := io.ReadFull(r.r, buf); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return &Frame{
+		Time: time.Unix(0, int64(binary.BigEndian.Uint64(buf[:8]))),
+		Data: buf[8:],
+	}, nil
+}
+
+// Close 关闭文件
+func (r *Reader) Close() error {
+	if r.closer != nil {
+		return r.closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package archive
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "room.dyfa")
+	start := time.Unix(1719159695, 0)
+	w, err := Create(path, Header{RoomId: "7383731312643626035", WebRid: "644826113301", StartTime: start})
+	if err != nil {
+		t.Fatal(err)
+	}
+	frames := [][]byte{{0x08, 0x01}, bytes.Repeat([]byte{0xab}, 300), {}}
+	for i, frame := range frames {
+		if err := w.WriteFrame(start.Add(time.Duration(i)*time.Second), frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if r.Header.WebRid != "644826113301" || !r.Header.StartTime.Equal(start) {
+		t.Fatalf("头部错误: %+v", r.Header)
+	}
+	for i, want := range frames {
+		frame, err := r.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(frame.Data, want) || !frame.Time.Equal(start.Add(time.Duration(i)*time.Second)) {
+			t.Fatalf("第 %d 帧错误: %+v", i, frame)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Fatalf("期望 io.EOF，得到 %v", err)
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "room.dyfa")
+	w, err := Create(path, Header{WebRid: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = w.WriteFrame(time.Now(), []byte("frame"))
+	_ = w.Close()
+
+	data, _ := os.ReadFile(path)
+	_ = os.WriteFile(path, data[:len(data)-2], 0o644)
+
+	r, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if _, err := r.Next(); err != io.ErrUnexpectedEOF {
+		t.Fatalf("期望 io.ErrUnexpectedEOF，得到 %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+// Package auth 校验接口调用方的 API Key 或 HS256 签名的 JWT，并按权限范围授权
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"douyinlive/config"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// 权限范围
+const (
+	ScopeRead  = "read"  // 订阅、查询直播间
+	ScopeStart = "start" // 开始抓取、录制、关注主播
+	ScopeStop  = "stop"  // 停止抓取、取消关注
+	ScopeAll   = "*"
+)
+
+// 认证方式
+const (
+	MethodNone   = "none" // 未启用鉴权
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+var (
+	// ErrUnauthorized 缺少凭证或凭证无效
+	ErrUnauthorized = errors.New("未认证或凭证无效")
+	// ErrTokenExpired JWT 已过期或尚未生效
+	ErrTokenExpired = errors.New("令牌已过期或尚未生效")
+)
+
+// Principal 已认证的调用方
+type Principal struct {
+	Name   string   `json:"name"`
+	Method string   `json:"method"`
+	Scopes []string `json:"scopes"`
+}
+
+// Has 是否拥有权限范围
+func (p *Principal) Has(scope string) bool {
+	if p == nil {
+		return false
+	}
+	for _, s := range p.Scopes {
+		if s == scope || s == ScopeAll {
+			return true
+		}
+	}
+	return false
+}
+
+// Anonymous 未启用鉴权时的调用方，拥有全部权限
+var Anonymous = &Principal{Name: "anonymous", Method: MethodNone, Scopes: []string{ScopeAll}}
+
+// Claims 支持的 JWT 字段，权限范围可用空格分隔的 scope 或数组 scopes
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Scope     string   `json:"scope,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+}
+
+// Authenticator 校验请求中的凭证
+type Authenticator struct {
+	keys   []config.APIKey
+	secret []byte
+	now    func() time.Time
+}
+
+// New 根据配置创建校验器
+func New(conf config.AuthConf) *Authenticator {
+	return &Authenticator{
+		keys:   conf.Keys,
+		secret: []byte(conf.JWTSecret),
+		now:    time.Now,
+	}
+}
+
+// Enabled 是否启用了鉴权
+func (a *Authenticator) Enabled() bool {
+	return len(a.keys) > 0 || len(a.secret) > 0
+}
+
+// Authenticate 校验请求，未启用鉴权时返回 Anonymous
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	if !a.Enabled() {
+		return Anonymous, nil
+	}
+	token := Token(r)
+	if token == "" {
+		return nil, ErrUnauthorized
+	}
+	for _, key := range a.keys {
+		if key.Key != "" && subtle.ConstantTimeCompare([]byte(key.Key), []byte(token)) == 1 {
+			return &Principal{Name: key.Name, Method: MethodAPIKey, Scopes: key.Scopes}, nil
+		}
+	}
+	if len(a.secret) > 0 && strings.Count(token, ".") == 2 {
+		claims, err := VerifyJWT(a.secret, token, a.now())
+		if err != nil {
+			return nil, err
+		}
+		scopes := claims.Scopes
+		if claims.Scope != "" {
+			scopes = append(scopes, strings.Fields(claims.Scope)...)
+		}
+		return &Principal{Name: claims.Subject, Method: MethodJWT, Scopes: scopes}, nil
+	}
+	return nil, ErrUnauthorized
+}
+
+// Token 读取请求中的凭证，依次为 Authorization: Bearer、X-API-Key 头与 token 参数
+//
+// 浏览器建立 WebSocket 连接时无法设置请求头，只能使用 token 参数
+func Token(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return r.URL.Query().Get("token")
+}
+
+// jwtHeader 只支持 HS256
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// SignJWT 使用 HS256 签发令牌
+func SignJWT(secret []byte, claims Claims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signingInput + "." + sign(secret, signingInput), nil
+}
+
+// VerifyJWT 校验 HS256 令牌的签名与有效期
+func VerifyJWT(secret []byte, token string, now time.Time) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrUnauthorized
+	}
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	var h struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(header, &h); err != nil || h.Alg != "HS256" {
+		return nil, ErrUnauthorized
+	}
+	expected := sign(secret, parts[0]+"."+parts[1])
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return nil, ErrUnauthorized
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrUnauthorized
+	}
+	if claims.ExpiresAt != 0 && now.Unix() >= claims.ExpiresAt {
+		return nil, ErrTokenExpired
+	}
+	if claims.NotBefore != 0 && now.Unix() < claims.NotBefore {
+		return nil, ErrTokenExpired
+	}
+	return &claims, nil
+}
+
+func sign(secret []byte, signingInput string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signingInput))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+type principalKey struct{}
+
+// WithPrincipal 将调用方保存到 context
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext 读取 context 中的调用方，不存在时返回 nil
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
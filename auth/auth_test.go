@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"douyinlive/config"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAPIKey(t *testing.T) {
+	a := New(config.AuthConf{Keys: []config.APIKey{{Name: "ops", Key: "secret-key", Scopes: []string{ScopeRead, ScopeStop}}}})
+
+	r := httptest.NewRequest("GET", "/api/rooms", nil)
+	if _, err := a.Authenticate(r); err != ErrUnauthorized {
+		t.Fatalf("缺少凭证应返回 ErrUnauthorized，得到 %v", err)
+	}
+	r.Header.Set("X-API-Key", "secret-key")
+	p, err := a.Authenticate(r)
+	if err != nil || p.Name != "ops" || !p.Has(ScopeStop) || p.Has(ScopeStart) {
+		t.Fatalf("API Key 校验错误: %+v %v", p, err)
+	}
+
+	r = httptest.NewRequest("GET", "/ws/start?token=wrong", nil)
+	if _, err := a.Authenticate(r); err != ErrUnauthorized {
+		t.Fatalf("错误的 Key 应返回 ErrUnauthorized，得到 %v", err)
+	}
+}
+
+func TestJWT(t *testing.T) {
+	secret := []byte("hmac-secret")
+	a := New(config.AuthConf{JWTSecret: string(secret)})
+	now := time.Unix(1719159695, 0)
+	a.now = func() time.Time { return now }
+
+	token, err := SignJWT(secret, Claims{Subject: "scheduler", Scope: "read start", ExpiresAt: now.Add(time.Hour).Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest("GET", "/api/rooms", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	p, err := a.Authenticate(r)
+	if err != nil || p.Name != "scheduler" || p.Method != MethodJWT || !p.Has(ScopeStart) || p.Has(ScopeStop) {
+		t.Fatalf("JWT 校验错误: %+v %v", p, err)
+	}
+
+	expired, _ := SignJWT(secret, Claims{Subject: "scheduler", ExpiresAt: now.Add(-time.Second).Unix()})
+	r.Header.Set("Authorization", "Bearer "+expired)
+	if _, err := a.Authenticate(r); err != ErrTokenExpired {
+		t.Fatalf("过期令牌应返回 ErrTokenExpired，得到 %v", err)
+	}
+
+	forged, _ := SignJWT([]byte("other"), Claims{Subject: "scheduler", Scopes: []string{ScopeAll}})
+	r.Header.Set("Authorization", "Bearer "+forged)
+	if _, err := a.Authenticate(r); err != ErrUnauthorized {
+		t.Fatalf("签名错误应返回 ErrUnauthorized，得到 %v", err)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	p, err := New(config.AuthConf{}).Authenticate(httptest.NewRequest("GET", "/", nil))
+	if err != nil || p != Anonymous || !p.Has(ScopeStop) {
+		t.Fatalf("未启用鉴权时应允许全部: %+v %v", p, err)
+	}
+}
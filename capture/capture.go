@@ -0,0 +1,243 @@
+// Package capture 将无法解析或未注册的消息落盘，作为补全 douyin.proto 的语料
+package capture
+
+import (
+	"crypto/sha1"
+	"douyinlive/rawproto"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 消息被收集的原因
+const (
+	ReasonUnregistered = "unregistered"  // 方法未在 MessageMap 中注册
+	ReasonDecodeFailed = "decode_failed" // 已注册但反序列化失败
+)
+
+const (
+	indexFileName = "index.json"
+	// DefaultMaxFileSize 单个语料文件的最大字节数，超过后轮转
+	DefaultMaxFileSize = 64 << 20
+	// DefaultMaxSamples 每种方法与结构组合最多保存的样本数
+	DefaultMaxSamples = 3
+	// indexFlushEvery 每收集多少条消息刷新一次索引
+	indexFlushEvery = 200
+)
+
+// Sample 一条被收集的消息
+type Sample struct {
+	Method  string    `json:"method"`
+	MsgId   int64     `json:"msg_id"`
+	RoomId  string    `json:"room_id"`
+	Reason  string    `json:"reason"`
+	Shape   string    `json:"shape"`
+	Time    time.Time `json:"time"`
+	Payload []byte    `json:"payload"`
+}
+
+// MethodStats 单个方法的收集统计
+type MethodStats struct {
+	Count     int64          `json:"count"`
+	Samples   int            `json:"samples"`
+	Shapes    map[string]int `json:"shapes"`
+	FirstSeen time.Time      `json:"first_seen"`
+	LastSeen  time.Time      `json:"last_seen"`
+}
+
+// index 持久化在 index.json 中的统计与去重信息
+type index struct {
+	Seq     int                     `json:"seq"`
+	Methods map[string]*MethodStats `json:"methods"`
+}
+
+// Store 按文件大小轮转的未知消息语料库
+type Store struct {
+	mu          sync.Mutex
+	dir         string
+	maxFileSize int64
+	maxSamples  int
+	index       *index
+	file        *os.File
+	size        int64
+	pending     int
+}
+
+// Open 打开或创建语料目录
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	idx, err := readIndex(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{
+		dir:         dir,
+		maxFileSize: DefaultMaxFileSize,
+		maxSamples:  DefaultMaxSamples,
+		index:       idx,
+	}, nil
+}
+
+// readIndex 读取索引文件，不存在时返回空索引
+func readIndex(dir string) (*index, error) {
+	idx := &index{Methods: make(map[string]*MethodStats)}
+	data, err := os.ReadFile(filepath.Join(dir, indexFileName))
+	if os.IsNotExist(err) {
+		return idx, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, fmt.Errorf("解析索引文件失败: %w", err)
+	}
+	if idx.Methods == nil {
+		idx.Methods = make(map[string]*MethodStats)
+	}
+	return idx, nil
+}
+
+// shapeKey 方法与结构签名的去重键
+func shapeKey(method, shape string) string {
+	sum := sha1.Sum([]byte(method + "|" + shape))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Capture 收集一条消息，同一方法与结构的样本达到上限后只计数不落盘，返回是否写入了样本
+func (s *Store) Capture(sample Sample) (bool, error) {
+	if sample.Shape == "" {
+		if fields, err := rawproto.Decode(sample.Payload); err == nil {
+			sample.Shape = rawproto.Shape(fields)
+		} else {
+			sample.Shape = "invalid"
+		}
+	}
+	if sample.Time.IsZero() {
+		sample.Time = time.Now()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats, ok := s.index.Methods[sample.Method]
+	if !ok {
+		stats = &MethodStats{Shapes: make(map[string]int), FirstSeen: sample.Time}
+		s.index.Methods[sample.Method] = stats
+	}
+	stats.Count++
+	stats.LastSeen = sample.Time
+	s.pending++
+
+	key := shapeKey(sample.Method, sample.Shape)
+	if stats.Shapes[key] >= s.maxSamples {
+		if s.pending >= indexFlushEvery {
+			return false, s.flushIndex()
+		}
+		return false, nil
+	}
+	if err := s.write(sample); err != nil {
+		return false, err
+	}
+	stats.Shapes[key]++
+	stats.Samples++
+	return true, s.flushIndex()
+}
+
+// write 追加一条样本，必要时轮转文件，调用方需持有锁
+func (s *Store) write(sample Sample) error {
+	line, err := json.Marshal(sample)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if s.file != nil && s.size+int64(len(line)) > s.maxFileSize {
+		if err := s.file.Close(); err != nil {
+			return err
+		}
+		s.file = nil
+		s.index.Seq++
+	}
+	if s.file == nil {
+		name := filepath.Join(s.dir, fmt.Sprintf("unknown-%06d.ndjson", s.index.Seq))
+		s.file, err = os.OpenFile(name, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		info, err := s.file.Stat()
+		if err != nil {
+			return err
+		}
+		s.size = info.Size()
+	}
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	return err
+}
+
+// flushIndex 写入索引文件，调用方需持有锁
+func (s *Store) flushIndex() error {
+	data, err := json.MarshalIndent(s.index, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(s.dir, indexFileName+".tmp")
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	s.pending = 0
+	return os.Rename(tmp, filepath.Join(s.dir, indexFileName))
+}
+
+// Close 刷新索引并关闭语料文件
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.flushIndex()
+	if s.file != nil {
+		if cerr := s.file.Close(); err == nil {
+			err = cerr
+		}
+		s.file = nil
+	}
+	return err
+}
+
+// MethodSummary 单个未知方法的汇总
+type MethodSummary struct {
+	Method   string    `json:"method"`
+	Count    int64     `json:"count"`
+	Samples  int       `json:"samples"`
+	Shapes   int       `json:"shapes"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// Summarize 读取语料目录的索引，按出现次数从高到低列出未知方法
+func Summarize(dir string) ([]MethodSummary, error) {
+	idx, err := readIndex(dir)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]MethodSummary, 0, len(idx.Methods))
+	for method, stats := range idx.Methods {
+		summaries = append(summaries, MethodSummary{
+			Method:   method,
+			Count:    stats.Count,
+			Samples:  stats.Samples,
+			Shapes:   len(stats.Shapes),
+			LastSeen: stats.LastSeen,
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Count != summaries[j].Count {
+			return summaries[i].Count > summaries[j].Count
+		}
+		return summaries[i].Method < summaries[j].Method
+	})
+	return summaries, nil
+}
@@ -0,0 +1,42 @@
+package capture
+
+import (
+	"testing"
+)
+
+func TestCaptureDedup(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	written := 0
+	for i := 0; i < 10; i++ {
+		// 值不同但结构相同，只保存 DefaultMaxSamples 个样本
+		ok, err := store.Capture(Sample{Method: "WebcastNewMessage", Reason: ReasonUnregistered, Payload: []byte{0x08, byte(i + 1)}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok {
+			written++
+		}
+	}
+	if _, err := store.Capture(Sample{Method: "WebcastOtherMessage", Reason: ReasonUnregistered, Payload: []byte{0x12, 0x00}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if written != DefaultMaxSamples {
+		t.Fatalf("写入样本数 = %d", written)
+	}
+
+	summaries, err := Summarize(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summaries) != 2 || summaries[0].Method != "WebcastNewMessage" || summaries[0].Count != 10 || summaries[0].Samples != DefaultMaxSamples || summaries[0].Shapes != 1 {
+		t.Fatalf("汇总结果错误: %+v", summaries)
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"douyinlive"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// 接口错误码
+const (
+	codeBadRequest    = "bad_request"
+	codeResolveFailed = "resolve_failed"
+	codeRoomLiving    = "room_already_living"
+	codeRoomNotFound  = "room_not_found"
+	codeStopTimeout   = "stop_timeout"
+	codeShuttingDown  = "shutting_down"
+	codeRoomsFull     = "rooms_full"
+	codeInternal      = "internal_error"
+)
+
+// 直播间状态
+const (
+	roomConnecting = "connecting" // 已提交，正在获取直播间信息或建立连接
+	roomRunning    = "running"    // 正在处理消息
+	roomFailed     = "failed"     // 开始抓取失败，未在抓取
+)
+
+// roomInfo 直播间列表中的一项
+type roomInfo struct {
+	WebRid        string     `json:"web_rid"`
+	WebcastRoomId string     `json:"webcast_room_id"`
+	SessionId     int        `json:"session_id"`
+	State         string     `json:"state"`
+	StartedBy     string     `json:"started_by"`
+	Recording     bool       `json:"recording"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	UptimeSeconds float64    `json:"uptime_seconds"`
+	Frames        int64      `json:"frames"`
+	Messages      int64      `json:"messages"`
+	Error         string     `json:"error,omitempty"` // 开始抓取失败的原因
+	FailedAt      *time.Time `json:"failed_at,omitempty"`
+}
+
+// roomDetail 直播间详情及实时统计
+type roomDetail struct {
+	roomInfo
+	Viewers      douyinlive.ViewerSummary        `json:"viewers"`
+	Social       douyinlive.SocialSummary        `json:"social"`
+	Explanations []douyinlive.ProductExplanation `json:"explanations"`
+}
+
+// bulkStopRequest 批量停止的请求，all 为 true 时停止全部直播间
+type bulkStopRequest struct {
+	WebRids []string `json:"web_rids"`
+	All     bool     `json:"all"`
+}
+
+// writeJSON 以 JSON 格式返回成功结果
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	jsonResponse, _ := json.Marshal(map[string]interface{}{
+		"is_ok": true,
+		"data":  data,
+	})
+	w.Write(jsonResponse)
+}
+
+// writeError 以 JSON 格式返回错误码与错误信息
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	jsonResponse, _ := json.Marshal(map[string]interface{}{
+		"is_ok":   false,
+		"code":    code,
+		"message": message,
+	})
+	w.Write(jsonResponse)
+}
+
+// describeRoom 直播间当前状态，连接尚未建立时只有 web_rid
+func describeRoom(webRid douyinlive.WebRid) (roomInfo, *douyinlive.DouyinLive) {
+	info := roomInfo{WebRid: string(webRid), State: roomConnecting, StartedBy: roomOwner(webRid)}
+	d := liveRoom(webRid)
+	if d == nil {
+		return info, nil
+	}
+	stats := d.Stats()
+	info.WebcastRoomId = string(d.RoomID())
+	info.SessionId = int(d.Session())
+	info.Recording = d.Recording()
+	info.Frames = stats.Frames
+	info.Messages = stats.Messages
+	if !stats.StartedAt.IsZero() {
+		info.State = roomRunning
+		info.StartedAt = &stats.StartedAt
+		info.UptimeSeconds = time.Since(stats.StartedAt).Seconds()
+	}
+	return info, d
+}
+
+// handleCreateRoom POST /api/rooms 开始抓取直播间，请求体与 /ws/start 的 start 指令相同
+func handleCreateRoom(w http.ResponseWriter, r *http.Request) {
+	var liveParam LiveParam
+	if err := json.NewDecoder(r.Body).Decode(&liveParam); err != nil {
+		writeError(w, http.StatusBadRequest, codeBadRequest, "请求解析失败: "+err.Error())
+		return
+	}
+	webRid, err := liveParam.webRid()
+	if errors.Is(err, errLegacyRoomId) {
+		writeError(w, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, codeResolveFailed, err.Error())
+		return
+	}
+	if webRid == "" {
+		writeError(w, http.StatusBadRequest, codeBadRequest, "缺少 web_rid 或 room")
+		return
+	}
+	if err := launchRoom(webRid, liveParam.session(), liveParam.Record, principalName(r)); err != nil {
+		switch {
+		case errors.Is(err, errRoomLiving):
+			writeError(w, http.StatusConflict, codeRoomLiving, err.Error())
+		case errors.Is(err, errRoomsFull):
+			writeError(w, http.StatusServiceUnavailable, codeRoomsFull, err.Error())
+		default:
+			writeError(w, http.StatusServiceUnavailable, codeShuttingDown, err.Error())
+		}
+		return
+	}
+	info, _ := describeRoom(webRid)
+	writeJSON(w, http.StatusAccepted, info)
+}
+
+// handleListRooms GET /api/rooms 列出正在抓取的直播间
+func handleListRooms(w http.ResponseWriter, r *http.Request) {
+	webRids := livingRooms()
+	infos := make([]roomInfo, 0, len(webRids))
+	for _, webRid := range webRids {
+		info, _ := describeRoom(webRid)
+		infos = append(infos, info)
+	}
+	writeJSON(w, http.StatusOK, infos)
+}
+
+// handleGetRoom GET /api/rooms/{id} 直播间详情及实时统计
+func handleGetRoom(w http.ResponseWriter, r *http.Request) {
+	webRid := douyinlive.WebRid(r.PathValue("id"))
+	if !isLiving(webRid) {
+		if failure, ok := lastFailure(webRid); ok {
+			writeJSON(w, http.StatusOK, roomDetail{roomInfo: roomInfo{
+				WebRid:    string(webRid),
+				State:     roomFailed,
+				StartedBy: failure.startedBy,
+				Error:     failure.err,
+				FailedAt:  &failure.at,
+			}})
+			return
+		}
+		writeError(w, http.StatusNotFound, codeRoomNotFound, "直播间并未在抓取弹幕信息")
+		return
+	}
+	info, d := describeRoom(webRid)
+	detail := roomDetail{roomInfo: info}
+	if d != nil {
+		// 连接建立前统计器尚未创建
+		if viewers := d.Viewers(); viewers != nil {
+			detail.Viewers = viewers.Summary()
+		}
+		if social := d.Social(); social != nil {
+			detail.Social = social.Summary()
+		}
+		if shopping := d.Shopping(); shopping != nil {
+			detail.Explanations = shopping.Explanations()
+		}
+	}
+	writeJSON(w, http.StatusOK, detail)
+}
+
+// handleDeleteRoom DELETE /api/rooms/{id} 停止抓取直播间
+func handleDeleteRoom(w http.ResponseWriter, r *http.Request) {
+	webRid := douyinlive.WebRid(r.PathValue("id"))
+	switch result := stopRoom(webRid); result {
+	case codeRoomNotFound:
+		writeError(w, http.StatusNotFound, result, "直播间并未在抓取弹幕信息")
+	case codeStopTimeout:
+		writeError(w, http.StatusServiceUnavailable, result, "已通知直播间停止，等待退出超时")
+	default:
+		writeJSON(w, http.StatusOK, map[string]string{"web_rid": string(webRid), "result": result})
+	}
+}
+
+// handleBulkStopRooms POST /api/rooms/stop 批量停止直播间，返回每个直播间的结果
+func handleBulkStopRooms(w http.ResponseWriter, r *http.Request) {
+	var req bulkStopRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, codeBadRequest, "请求解析失败: "+err.Error())
+		return
+	}
+	webRids := livingRooms()
+	if !req.All {
+		webRids = webRids[:0]
+		for _, webRid := range req.WebRids {
+			webRids = append(webRids, douyinlive.WebRid(webRid))
+		}
+	}
+	if len(webRids) == 0 {
+		writeError(w, http.StatusBadRequest, codeBadRequest, "缺少 web_rids 或 all")
+		return
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make(map[string]string, len(webRids))
+	for _, webRid := range webRids {
+		wg.Add(1)
+		go func(webRid douyinlive.WebRid) {
+			defer wg.Done()
+			result := stopRoom(webRid)
+			mu.Lock()
+			results[string(webRid)] = result
+			mu.Unlock()
+		}(webRid)
+	}
+	wg.Wait()
+	writeJSON(w, http.StatusOK, results)
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"douyinlive"
+	"douyinlive/archive"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func roomAPI() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/rooms", handleCreateRoom)
+	mux.HandleFunc("GET /api/rooms", handleListRooms)
+	mux.HandleFunc("POST /api/rooms/stop", handleBulkStopRooms)
+	mux.HandleFunc("GET /api/rooms/{id}", handleGetRoom)
+	mux.HandleFunc("DELETE /api/rooms/{id}", handleDeleteRoom)
+	return mux
+}
+
+type apiResponse struct {
+	IsOk bool            `json:"is_ok"`
+	Code string          `json:"code"`
+	Data json.RawMessage `json:"data"`
+}
+
+func callAPI(t *testing.T, method, path, body string) (int, apiResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	roomAPI().ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+	var resp apiResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	return w.Code, resp
+}
+
+// addRoom 将直播间标记为正在抓取，测试结束后移除
+func addRoom(t *testing.T, webRid douyinlive.WebRid, d *douyinlive.DouyinLive) *roomHandle {
+	t.Helper()
+	handle := &roomHandle{webRid: webRid, startedBy: "test", cancel: func() {}, done: make(chan struct{}), live: d}
+	roomsMu.Lock()
+	living[webRid] = handle
+	roomsMu.Unlock()
+	t.Cleanup(func() { removeRoom(handle) })
+	return handle
+}
+
+func TestRoomAPI(t *testing.T) {
+	d := douyinlive.NewReplayLive(archive.Header{WebRid: "644826113301", RoomId: "7383731312643626035"})
+	addRoom(t, d.WebRid(), d)
+	if err := launchRoom(d.WebRid(), 0, false, "test"); err != errRoomLiving {
+		t.Fatalf("重复开始应返回 errRoomLiving，得到 %v", err)
+	}
+
+	status, resp := callAPI(t, http.MethodGet, "/api/rooms", "")
+	var list []roomInfo
+	_ = json.Unmarshal(resp.Data, &list)
+	if status != http.StatusOK || len(list) != 1 || list[0].WebcastRoomId != "7383731312643626035" || list[0].State != roomConnecting {
+		t.Fatalf("列表错误: %d %s", status, resp.Data)
+	}
+
+	status, resp = callAPI(t, http.MethodGet, "/api/rooms/644826113301", "")
+	var detail roomDetail
+	_ = json.Unmarshal(resp.Data, &detail)
+	if status != http.StatusOK || detail.WebRid != "644826113301" {
+		t.Fatalf("详情错误: %d %s", status, resp.Data)
+	}
+
+	if status, resp = callAPI(t, http.MethodPost, "/api/rooms", `{"web_rid":"644826113301"}`); status != http.StatusConflict || resp.Code != codeRoomLiving {
+		t.Fatalf("重复开始应返回冲突: %d %+v", status, resp)
+	}
+	if status, resp = callAPI(t, http.MethodPost, "/api/rooms", `{}`); status != http.StatusBadRequest || resp.Code != codeBadRequest {
+		t.Fatalf("缺少参数应返回错误: %d %+v", status, resp)
+	}
+	if status, resp = callAPI(t, http.MethodPost, "/api/rooms", `{"room_id":644826113301}`); status != http.StatusBadRequest || resp.Code != codeBadRequest {
+		t.Fatalf("旧协议的 room_id 应返回错误: %d %+v", status, resp)
+	}
+	if status, resp = callAPI(t, http.MethodGet, "/api/rooms/1", ""); status != http.StatusNotFound || resp.Code != codeRoomNotFound {
+		t.Fatalf("不存在的直播间应返回 404: %d %+v", status, resp)
+	}
+	if status, resp = callAPI(t, http.MethodDelete, "/api/rooms/1", ""); status != http.StatusNotFound || resp.Code != codeRoomNotFound {
+		t.Fatalf("不存在的直播间应返回 404: %d %+v", status, resp)
+	}
+
+	status, resp = callAPI(t, http.MethodPost, "/api/rooms/stop", `{"web_rids":["1","2"]}`)
+	var results map[string]string
+	_ = json.Unmarshal(resp.Data, &results)
+	if status != http.StatusOK || results["1"] != codeRoomNotFound || results["2"] != codeRoomNotFound {
+		t.Fatalf("批量停止错误: %d %s", status, resp.Data)
+	}
+}
+
+// blockingSource 关闭前一直阻塞的帧来源
+type blockingSource struct{ closed chan struct{} }
+
+func (s *blockingSource) ReadFrame() ([]byte, error) {
+	<-s.closed
+	return nil, io.EOF
+}
+
+func (s *blockingSource) WriteFrame(data []byte) error { return nil }
+
+func (s *blockingSource) Close() error {
+	close(s.closed)
+	return nil
+}
+
+func TestRoomDetailWhileStarting(t *testing.T) {
+	d := douyinlive.NewReplayLive(archive.Header{WebRid: "23020419981", RoomId: "7383731312643626036"})
+	addRoom(t, d.WebRid(), d)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.StartSource(ctx, &blockingSource{closed: make(chan struct{})}, 42)
+	}()
+	// 处理协程写入场次与统计器时并发查询详情，配合 -race 检查
+	for i := 0; i < 50; i++ {
+		if status, resp := callAPI(t, http.MethodGet, "/api/rooms/23020419981", ""); status != http.StatusOK {
+			t.Fatalf("详情错误: %d %s", status, resp.Data)
+		}
+	}
+	cancel()
+	<-done
+	if d.Session() != 42 || d.Viewers() == nil {
+		t.Fatalf("场次或统计器未设置: %d %v", d.Session(), d.Viewers())
+	}
+}
+
+func TestRoomStartFailure(t *testing.T) {
+	c := NewClient("starter", nil, nil)
+	c.subscribe("10000000001", nil)
+	StoreConnection(c.id, c)
+	defer DeleteConnection(c.id)
+	t.Cleanup(func() {
+		roomsMu.Lock()
+		delete(failures, "10000000001")
+		roomsMu.Unlock()
+	})
+
+	failRoom(&roomHandle{webRid: "10000000001", startedBy: "test"}, errors.New("直播间不存在"))
+	select {
+	case out := <-c.out:
+		var notification struct {
+			IsOk bool         `json:"is_ok"`
+			Data responseData `json:"data"`
+		}
+		if err := json.Unmarshal(out, &notification); err != nil || notification.IsOk || notification.Data.WebRid != "10000000001" {
+			t.Fatalf("失败通知错误: %s", out)
+		}
+	default:
+		t.Fatal("开始抓取失败时应通知订阅者")
+	}
+
+	status, resp := callAPI(t, http.MethodGet, "/api/rooms/10000000001", "")
+	var detail roomDetail
+	_ = json.Unmarshal(resp.Data, &detail)
+	if status != http.StatusOK || detail.State != roomFailed || !strings.Contains(detail.Error, "直播间不存在") || detail.StartedBy != "test" {
+		t.Fatalf("详情应返回失败状态: %d %s", status, resp.Data)
+	}
+}
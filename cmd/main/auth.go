@@ -0,0 +1,55 @@
+package main
+
+import (
+	"douyinlive/auth"
+	"douyinlive/config"
+	"errors"
+	"net/http"
+)
+
+// 鉴权错误码
+const (
+	codeUnauthorized = "unauthorized"
+	codeForbidden    = "forbidden"
+)
+
+// authenticator 接口鉴权，未配置 API Key 与 JWT 密钥时允许所有请求
+var authenticator = auth.New(config.AuthConf{})
+
+// authenticate 校验请求凭证并检查权限范围，失败时写入 401 或 403 并返回 nil
+func authenticate(w http.ResponseWriter, r *http.Request, scope string) *auth.Principal {
+	principal, err := authenticator.Authenticate(r)
+	if err != nil {
+		message := "缺少凭证或凭证无效"
+		if errors.Is(err, auth.ErrTokenExpired) {
+			message = err.Error()
+		}
+		w.Header().Set("WWW-Authenticate", `Bearer realm="douyinlive"`)
+		writeError(w, http.StatusUnauthorized, codeUnauthorized, message)
+		return nil
+	}
+	if !principal.Has(scope) {
+		writeError(w, http.StatusForbidden, codeForbidden, "缺少权限: "+scope)
+		return nil
+	}
+	return principal
+}
+
+// requireScope 要求调用方拥有权限范围，调用方保存在请求的 context 中
+func requireScope(scope string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := authenticate(w, r, scope)
+		if principal == nil {
+			return
+		}
+		next(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	}
+}
+
+// principalName 请求的调用方名称，用于记录开始抓取直播间的调用方
+func principalName(r *http.Request) string {
+	if principal := auth.FromContext(r.Context()); principal != nil {
+		return principal.Name
+	}
+	return auth.Anonymous.Name
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"douyinlive/auth"
+	"douyinlive/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireScope(t *testing.T) {
+	defer func(a *auth.Authenticator) { authenticator = a }(authenticator)
+	authenticator = auth.New(config.AuthConf{Keys: []config.APIKey{
+		{Name: "viewer", Key: "read-key", Scopes: []string{auth.ScopeRead}},
+		{Name: "admin", Key: "admin-key", Scopes: []string{auth.ScopeAll}},
+	}})
+
+	var startedBy string
+	handler := requireScope(auth.ScopeStart, func(w http.ResponseWriter, r *http.Request) {
+		startedBy = principalName(r)
+		writeJSON(w, http.StatusAccepted, nil)
+	})
+	cases := []struct {
+		key    string
+		status int
+	}{
+		{"", http.StatusUnauthorized},
+		{"wrong", http.StatusUnauthorized},
+		{"read-key", http.StatusForbidden},
+		{"admin-key", http.StatusAccepted},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest(http.MethodPost, "/api/rooms", nil)
+		if c.key != "" {
+			r.Header.Set("Authorization", "Bearer "+c.key)
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		if w.Code != c.status {
+			t.Fatalf("key %q: 期望 %d，得到 %d", c.key, c.status, w.Code)
+		}
+	}
+	if startedBy != "admin" {
+		t.Fatalf("调用方记录错误: %q", startedBy)
+	}
+}
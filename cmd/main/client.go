@@ -0,0 +1,221 @@
+package main
+
+import (
+	"douyinlive"
+	"douyinlive/auth"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// 客户端指令，为空时视为 start
+const (
+	actionStart       = "start"
+	actionSubscribe   = "subscribe"
+	actionUnsubscribe = "unsubscribe"
+)
+
+const (
+	// writeWait 单次写入的超时时间
+	writeWait = 10 * time.Second
+	// pongWait 等待客户端 pong 的超时时间，超时视为断开
+	pongWait = 60 * time.Second
+	// pingPeriod 发送 ping 的间隔，需小于 pongWait
+	pingPeriod = pongWait * 9 / 10
+	// maxMessageSize 客户端指令的最大字节数
+	maxMessageSize = 64 << 10
+	// sendQueueSize 待发送队列长度，队列满时视为慢客户端并断开
+	sendQueueSize = 256
+)
+
+var (
+	errClientClosed = errors.New("客户端已断开")
+	errSlowClient   = errors.New("客户端接收过慢，已断开")
+)
+
+// Client 一个 WebSocket 客户端连接及其订阅的直播间
+//
+// 所有写入都经过 out 队列由 writePump 串行完成，gorilla/websocket 不支持并发写；
+// 直播间的读取循环调用 Send 时不会阻塞，队列满时直接断开该客户端
+type Client struct {
+	id        string
+	conn      *websocket.Conn
+	out       chan []byte
+	principal *auth.Principal // 建立连接时认证的调用方
+
+	done      chan struct{}
+	closeOnce sync.Once
+	closeMsg  []byte // 关闭帧，包含关闭原因
+
+	mu   sync.Mutex
+	subs map[douyinlive.WebRid]map[string]bool // 直播间 -> 订阅的消息类型，nil 表示全部类型
+}
+
+// NewClient 创建客户端，需调用 Serve 开始收发消息
+func NewClient(id string, conn *websocket.Conn, principal *auth.Principal) *Client {
+	return &Client{
+		id:        id,
+		conn:      conn,
+		out:       make(chan []byte, sendQueueSize),
+		principal: principal,
+		done:      make(chan struct{}),
+		subs:      make(map[douyinlive.WebRid]map[string]bool),
+	}
+}
+
+// Serve 启动写入协程并循环读取客户端消息，连接断开后返回
+func (c *Client) Serve(handle func(message []byte) error) {
+	go c.writePump()
+	defer c.Close()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		_, message, err := c.conn.ReadMessage()
+		if err != nil {
+			log.Printf("读取消息失败: %v\n", err)
+			return
+		}
+		// 任何消息都说明连接仍然可用
+		_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		if err := handle(message); err != nil {
+			log.Printf("处理客户端 %s 消息失败: %v\n", c.id, err)
+			return
+		}
+	}
+}
+
+// writePump 唯一的写入协程，负责发送队列中的消息与定时 ping
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.Close()
+		c.conn.Close()
+	}()
+	for {
+		select {
+		case data := <-c.out:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				log.Printf("发送消息到客户端 %s 失败: %v\n", c.id, err)
+				return
+			}
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-c.done:
+			c.drain()
+			_ = c.conn.WriteControl(websocket.CloseMessage, c.closeMsg, time.Now().Add(writeWait))
+			return
+		}
+	}
+}
+
+// drain 关闭前尽量发送队列中剩余的消息，例如关闭服务的通知
+func (c *Client) drain() {
+	for {
+		select {
+		case data := <-c.out:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// Close 通知写入协程发送关闭帧并关闭连接，可重复调用
+func (c *Client) Close() {
+	c.CloseWithReason(websocket.CloseNormalClosure, "")
+}
+
+// CloseWithReason 以指定的关闭码与原因关闭连接，只有第一次调用生效
+func (c *Client) CloseWithReason(code int, reason string) {
+	c.closeOnce.Do(func() {
+		c.closeMsg = websocket.FormatCloseMessage(code, reason)
+		close(c.done)
+	})
+}
+
+// subscribe 订阅直播间，methods 为空时订阅全部类型，重复订阅会替换原有的类型过滤
+func (c *Client) subscribe(webRid douyinlive.WebRid, methods []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.subs[webRid] = methodSet(methods)
+}
+
+// unsubscribe 取消订阅直播间
+func (c *Client) unsubscribe(webRid douyinlive.WebRid) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.subs, webRid)
+}
+
+// wants 是否需要推送该消息，订阅了直播间的客户端总能收到状态通知
+func (c *Client) wants(eventData *douyinlive.Event) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	methods, ok := c.subs[eventData.WebRid]
+	if !ok {
+		return false
+	}
+	return methods == nil || methods[eventData.Method] || notificationMethods[eventData.Method]
+}
+
+// subscriptions 当前订阅，用于回复客户端
+func (c *Client) subscriptions() map[douyinlive.WebRid][]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	subs := make(map[douyinlive.WebRid][]string, len(c.subs))
+	for webRid, methods := range c.subs {
+		list := make([]string, 0, len(methods))
+		for method := range methods {
+			list = append(list, method)
+		}
+		subs[webRid] = list
+	}
+	return subs
+}
+
+// Send 将消息放入发送队列，不会阻塞；队列已满时断开该客户端
+func (c *Client) Send(data []byte) error {
+	select {
+	case <-c.done:
+		return errClientClosed
+	default:
+	}
+	select {
+	case c.out <- data:
+		return nil
+	default:
+		dispatchDropped.Inc()
+		log.Printf("客户端 %s 接收过慢，断开连接\n", c.id)
+		c.Close()
+		return errSlowClient
+	}
+}
+
+// reply 回复客户端指令的处理结果
+func (c *Client) reply(isOk bool, message string, data interface{}) error {
+	response := map[string]interface{}{
+		"is_ok":   isOk,
+		"message": message,
+	}
+	if data != nil {
+		response["data"] = data
+	}
+	out, _ := json.Marshal(response)
+	return c.Send(out)
+}
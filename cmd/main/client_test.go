@@ -0,0 +1,143 @@
+package main
+
+import (
+	"douyinlive"
+	"douyinlive/auth"
+	"douyinlive/generated"
+	"douyinlive/generated/douyin"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/protobuf/proto"
+)
+
+// testEvent 构造属于 webRid 直播间的事件，已注册的消息与 DouyinLive 一样预先解码
+func testEvent(webRid douyinlive.WebRid, message *douyin.Message) *douyinlive.Event {
+	event := &douyinlive.Event{Message: message, WebRid: webRid}
+	if newMessage, ok := generated.MessageMap[message.Method]; ok {
+		msg := newMessage()
+		if event.DecodeErr = proto.Unmarshal(message.Payload, msg); event.DecodeErr == nil {
+			event.Decoded = msg
+		}
+	}
+	return event
+}
+
+func TestClientWants(t *testing.T) {
+	c := NewClient("1", nil, nil)
+	c.subscribe("644826113301", []string{"Chat", "Gift"})
+	c.subscribe("23020419981", nil)
+
+	cases := []struct {
+		message *douyinlive.Event
+		want    bool
+	}{
+		{testEvent("644826113301", &douyin.Message{Method: "WebcastChatMessage"}), true},
+		{testEvent("644826113301", &douyin.Message{Method: "WebcastLikeMessage"}), false},
+		{testEvent("644826113301", &douyin.Message{Method: "OffNotification"}), true},
+		{testEvent("23020419981", &douyin.Message{Method: "WebcastLikeMessage"}), true},
+		{testEvent("1", &douyin.Message{Method: "SuccessNotification"}), false},
+	}
+	for _, tc := range cases {
+		if got := c.wants(tc.message); got != tc.want {
+			t.Fatalf("%s %s: 期望 %v", tc.message.WebRid, tc.message.Method, tc.want)
+		}
+	}
+
+	c.unsubscribe("23020419981")
+	if c.wants(testEvent("23020419981", &douyin.Message{Method: "OffNotification"})) {
+		t.Fatal("取消订阅后不应再推送")
+	}
+}
+
+func TestClientSlowEviction(t *testing.T) {
+	dropped := testutil.ToFloat64(dispatchDropped)
+	c := NewClient("1", nil, nil)
+	for i := 0; i < sendQueueSize; i++ {
+		if err := c.Send([]byte("x")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Send([]byte("x")); err != errSlowClient {
+		t.Fatalf("期望 errSlowClient，得到 %v", err)
+	}
+	if testutil.ToFloat64(dispatchDropped) != dropped+1 {
+		t.Fatalf("丢弃计数错误: %v", testutil.ToFloat64(dispatchDropped))
+	}
+	if err := c.Send([]byte("x")); err != errClientClosed {
+		t.Fatalf("期望 errClientClosed，得到 %v", err)
+	}
+}
+
+func TestClientServe(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		c := NewClient("1", conn, auth.Anonymous)
+		c.Serve(func(message []byte) error {
+			return c.Send(append([]byte("echo:"), message...))
+		})
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	for i := 0; i < 3; i++ {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte("hi")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(message) != "echo:hi" {
+			t.Fatalf("回复错误: %s", message)
+		}
+	}
+}
+
+func TestClientCloseWithReason(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		c := NewClient("1", conn, auth.Anonymous)
+		c.Serve(func(message []byte) error {
+			// 关闭前放入队列的消息仍会发送
+			_ = c.Send([]byte("bye"))
+			c.CloseWithReason(websocket.CloseGoingAway, shutdownReason)
+			return nil
+		})
+	}))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("hi")); err != nil {
+		t.Fatal(err)
+	}
+	if _, message, err := conn.ReadMessage(); err != nil || string(message) != "bye" {
+		t.Fatalf("期望收到关闭前的消息: %s %v", message, err)
+	}
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseGoingAway) || !strings.Contains(err.Error(), shutdownReason) {
+		t.Fatalf("期望以 going away 关闭: %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"douyinlive"
+	"douyinlive/generated/douyin"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// envelopeVersion 事件信封的协议版本，字段不兼容变更时递增
+// 2: room_id 更名为 webcast_room_id，room_id 只在通知中保留旧协议的含义
+const envelopeVersion = 2
+
+// forwardOptions 转发消息时的 protojson 选项，由 config.ForwardConf 设置
+var forwardOptions protojson.MarshalOptions
+
+// envelope 转发给客户端的直播事件
+type envelope struct {
+	V             int             `json:"v"`
+	Type          string          `json:"type"` // 消息方法名，如 WebcastChatMessage
+	WebRid        string          `json:"web_rid"`
+	WebcastRoomId string          `json:"webcast_room_id"` // 抖音内部的 webcast room_id
+	SessionId     int             `json:"session_id"`
+	MsgId         int64           `json:"msg_id"`
+	Ts            int64           `json:"ts"` // 消息创建时间（毫秒），消息中没有时为收到的时间
+	Data          json.RawMessage `json:"data"`
+}
+
+// forwardEvent 将直播事件推送给订阅了该类型的客户端，未注册或解码失败的消息不转发
+func forwardEvent(eventData *douyinlive.Event) {
+	if notificationMethods[eventData.Method] || eventData.Decoded == nil {
+		return
+	}
+	var subscribers []*Client
+	RangeSubscribers(eventData, func(agentID string, c *Client) {
+		subscribers = append(subscribers, c)
+	})
+	if len(subscribers) == 0 {
+		return
+	}
+
+	out, err := encodeEvent(eventData, forwardOptions)
+	if err != nil {
+		log.Printf("编码消息失败: %v, 方法: %s\n", err, eventData.Method)
+		return
+	}
+	for _, c := range subscribers {
+		if err := c.Send(out); err != nil {
+			log.Printf("发送消息到客户端 %s 失败: %v\n", c.id, err)
+		}
+	}
+}
+
+// errNotDecoded 消息未注册或解码失败，无法编码为事件信封
+var errNotDecoded = errors.New("消息未解码")
+
+// encodeEvent 将已解码的消息编码为事件信封
+func encodeEvent(eventData *douyinlive.Event, options protojson.MarshalOptions) ([]byte, error) {
+	msg := eventData.Decoded
+	if msg == nil {
+		return nil, fmt.Errorf("%w: %s", errNotDecoded, eventData.Method)
+	}
+	data, err := options.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	ts := time.Now().UnixMilli()
+	if cm, ok := msg.(interface{ GetCommon() *douyin.Common }); ok {
+		if createTime := cm.GetCommon().GetCreateTime(); createTime > 0 {
+			ts = int64(createTime)
+		}
+	}
+	return json.Marshal(envelope{
+		V:             envelopeVersion,
+		Type:          eventData.Method,
+		WebRid:        string(eventData.WebRid),
+		WebcastRoomId: string(eventData.RoomID),
+		SessionId:     int(eventData.Session),
+		MsgId:         eventData.MsgId,
+		Ts:            ts,
+		Data:          data,
+	})
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"douyinlive/generated/douyin"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestEncodeEvent(t *testing.T) {
+	payload, _ := proto.Marshal(&douyin.ChatMessage{
+		Common:  &douyin.Common{CreateTime: 1719159695790},
+		User:    &douyin.User{NickName: "观众"},
+		Content: "你好",
+	})
+	message := testEvent("644826113301", &douyin.Message{Method: "WebcastChatMessage", Payload: payload, MsgId: 9})
+	message.RoomID = "7383731312643626035"
+
+	out, err := encodeEvent(message, protojson.MarshalOptions{UseProtoNames: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var event struct {
+		envelope
+		Data struct {
+			Content string `json:"content"`
+			Common  struct {
+				CreateTime string `json:"create_time"`
+			} `json:"common"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(out, &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.V != envelopeVersion || event.Type != "WebcastChatMessage" || event.MsgId != 9 || event.Ts != 1719159695790 || event.WebcastRoomId != "7383731312643626035" {
+		t.Fatalf("信封错误: %s", out)
+	}
+	if event.Data.Content != "你好" || event.Data.Common.CreateTime != "1719159695790" {
+		t.Fatalf("消息内容错误: %s", out)
+	}
+
+	if _, err := encodeEvent(testEvent("1", &douyin.Message{Method: "WebcastUnknownMessage"}), forwardOptions); !errors.Is(err, errNotDecoded) {
+		t.Fatalf("未知消息应返回 errNotDecoded，得到 %v", err)
+	}
+}
+
+func TestForwardEventSkipsUndecoded(t *testing.T) {
+	c := NewClient("forward", nil, nil)
+	c.subscribe("1", nil)
+	StoreConnection(c.id, c)
+	defer DeleteConnection(c.id)
+
+	forwardEvent(testEvent("1", &douyin.Message{Method: "WebcastUnknownMessage", Payload: []byte{1}}))
+	forwardEvent(testEvent("1", &douyin.Message{Method: "WebcastChatMessage", Payload: []byte{0x0a, 0xff}}))
+	if len(c.out) != 0 {
+		t.Fatalf("未注册或解码失败的消息不应转发，队列中有 %d 条", len(c.out))
+	}
+	chat, _ := proto.Marshal(&douyin.ChatMessage{Content: "你好"})
+	forwardEvent(testEvent("1", &douyin.Message{Method: "WebcastChatMessage", Payload: chat}))
+	if len(c.out) != 1 {
+		t.Fatalf("已解码的消息应转发，队列中有 %d 条", len(c.out))
+	}
+}
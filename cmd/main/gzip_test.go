@@ -1,9 +1,9 @@
 package main
 
 import (
-	douyinlive "DouyinLive"
 	"bytes"
 	"compress/gzip"
+	"douyinlive"
 	"io"
 
 	"testing"
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"douyinlive/database"
+	"douyinlive/jsScript"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// 健康状态
+const (
+	healthOK          = "ok"
+	healthDegraded    = "degraded"    // 非关键依赖不可用，仍可提供服务
+	healthUnavailable = "unavailable" // 关键依赖不可用或服务正在关闭
+	checkFail         = "fail"
+)
+
+// errSchemaPending 数据库可用但表结构尚未创建，后台重试完成后恢复
+var errSchemaPending = errors.New("表结构尚未创建，等待重试")
+
+// checkTimeout 单项检查的超时时间
+const checkTimeout = 2 * time.Second
+
+// processStart 进程启动时间
+var processStart = time.Now()
+
+// checkResult 单项检查的结果
+type checkResult struct {
+	Status    string                 `json:"status"`
+	Critical  bool                   `json:"critical"` // 关键检查失败时 /readyz 返回 503
+	Error     string                 `json:"error,omitempty"`
+	LatencyMs float64                `json:"latency_ms"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+// readinessCheck 一项就绪检查
+type readinessCheck struct {
+	name     string
+	critical bool
+	run      func(ctx context.Context) (map[string]interface{}, error)
+}
+
+// readinessChecks /readyz 执行的检查
+var readinessChecks = []readinessCheck{
+	{name: "signer", critical: true, run: checkSigner},
+	{name: "database", critical: false, run: checkDatabase},
+	{name: "rooms", critical: true, run: checkRooms},
+}
+
+// handleHealthz GET /healthz 进程存活即返回 200
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, http.StatusOK, map[string]interface{}{
+		"is_ok":          true,
+		"status":         healthOK,
+		"uptime_seconds": time.Since(processStart).Seconds(),
+	})
+}
+
+// handleReadyz GET /readyz 检查依赖是否可用，关键检查失败或服务正在关闭时返回 503，
+// 只有数据库等非关键依赖不可用时返回 200 与 degraded
+func handleReadyz(w http.ResponseWriter, r *http.Request) {
+	results := make(map[string]checkResult, len(readinessChecks))
+	status := healthOK
+	for _, check := range readinessChecks {
+		result := runCheck(r.Context(), check)
+		results[check.name] = result
+		if result.Status == checkFail {
+			if check.critical {
+				status = healthUnavailable
+			} else if status == healthOK {
+				status = healthDegraded
+			}
+		}
+	}
+	if !acceptingRooms() {
+		status = healthUnavailable
+	}
+	code := http.StatusOK
+	if status == healthUnavailable {
+		code = http.StatusServiceUnavailable
+	}
+	writeHealth(w, code, map[string]interface{}{
+		"is_ok":  status != healthUnavailable,
+		"status": status,
+		"checks": results,
+	})
+}
+
+// runCheck 在超时时间内执行检查并记录耗时
+func runCheck(ctx context.Context, check readinessCheck) checkResult {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	start := time.Now()
+	details, err := check.run(ctx)
+	result := checkResult{
+		Status:    healthOK,
+		Critical:  check.critical,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+		Details:   details,
+	}
+	if err != nil {
+		result.Status = checkFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// checkSigner 签名脚本能否加载并生成签名
+func checkSigner(ctx context.Context) (map[string]interface{}, error) {
+	return nil, jsScript.Check()
+}
+
+// checkDatabase 数据库能否 ping 通，表结构尚未创建时同样视为不可用
+func checkDatabase(ctx context.Context) (map[string]interface{}, error) {
+	details := map[string]interface{}{
+		"enabled":  database.Enabled(),
+		"driver":   database.Driver(),
+		"migrated": schemaMigrated.Load(),
+	}
+	if err := database.Ping(ctx); err != nil {
+		return details, err
+	}
+	if database.Enabled() && !schemaMigrated.Load() {
+		return details, errSchemaPending
+	}
+	return details, nil
+}
+
+// checkRooms 正在抓取的直播间是否已达上限
+func checkRooms(ctx context.Context) (map[string]interface{}, error) {
+	details := map[string]interface{}{
+		"active":    len(livingRooms()),
+		"max_rooms": serverConf.MaxRooms,
+	}
+	if roomsSaturated() {
+		return details, errRoomsFull
+	}
+	return details, nil
+}
+
+// writeHealth 输出健康检查结果，禁止缓存
+func writeHealth(w http.ResponseWriter, status int, data map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	jsonResponse, _ := json.Marshal(data)
+	w.Write(jsonResponse)
+}
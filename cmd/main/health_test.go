@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"douyinlive/config"
+	"douyinlive/database"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+type readyResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]checkResult `json:"checks"`
+}
+
+func callReadyz(t *testing.T) (int, readyResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	handleReadyz(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var resp readyResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return w.Code, resp
+}
+
+func TestReadyz(t *testing.T) {
+	// 未连接数据库时降级但仍就绪
+	status, resp := callReadyz(t)
+	if status != http.StatusOK || resp.Status != healthDegraded {
+		t.Fatalf("期望 200 degraded: %d %+v", status, resp)
+	}
+	if resp.Checks["database"].Status != checkFail || resp.Checks["signer"].Status != healthOK {
+		t.Fatalf("检查结果错误: %+v", resp.Checks)
+	}
+
+	// 直播间达到上限时未就绪
+	defer func(max int) { serverConf.MaxRooms = max }(serverConf.MaxRooms)
+	serverConf.MaxRooms = 1
+	addRoom(t, "644826113301", nil)
+	status, resp = callReadyz(t)
+	if status != http.StatusServiceUnavailable || resp.Status != healthUnavailable || resp.Checks["rooms"].Status != checkFail {
+		t.Fatalf("期望 503 unavailable: %d %+v", status, resp)
+	}
+	if err := launchRoom("23020419981", 0, false, "test"); err != errRoomsFull {
+		t.Fatalf("期望 errRoomsFull，得到 %v", err)
+	}
+}
+
+func TestReadyzDatabaseRecovery(t *testing.T) {
+	// MySQL 未启动时仍创建连接池，恢复后无需重启
+	err := database.InitRMSDB(config.MySQLConf{Username: "root", Host: "127.0.0.1:1", Database: "douyinlive"})
+	if err == nil || !database.Enabled() {
+		t.Fatalf("MySQL 不可用时应返回错误并保留连接池: %v", err)
+	}
+	database.Close()
+
+	// 表结构未创建时数据库检查失败，创建后恢复
+	if err := database.InitSQLite(filepath.Join(t.TempDir(), "douyinlive.db")); err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	defer schemaMigrated.Store(false)
+	if _, resp := callReadyz(t); resp.Checks["database"].Error != errSchemaPending.Error() {
+		t.Fatalf("期望表结构未创建: %+v", resp.Checks["database"])
+	}
+	migrateSchema(context.Background())
+	if _, resp := callReadyz(t); resp.Checks["database"].Status != healthOK {
+		t.Fatalf("建表后数据库检查应恢复: %+v", resp.Checks["database"])
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"douyinlive/generated/douyin"
+	"douyinlive/rawproto"
+	"douyinlive/utils"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// 识别出的数据类型
+const (
+	kindPushFrame = "push_frame"
+	kindResponse  = "response"
+	kindMessage   = "message"
+	kindPayload   = "payload"
+	kindUnknown   = "unknown"
+)
+
+var hexRegexp = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+
+// inspectResult inspect 命令的输出
+type inspectResult struct {
+	Kind     string            `json:"kind"`
+	Gzip     bool              `json:"gzip,omitempty"`
+	Frame    json.RawMessage   `json:"frame,omitempty"`
+	Response json.RawMessage   `json:"response,omitempty"`
+	Messages []inspectMessage  `json:"messages,omitempty"`
+	Fields   []*rawproto.Field `json:"fields,omitempty"`
+}
+
+// inspectMessage 单条 Message 的解析结果，已知类型输出 protojson，未知类型输出字段树
+type inspectMessage struct {
+	Method string            `json:"method"`
+	MsgId  int64             `json:"msg_id,omitempty"`
+	Data   json.RawMessage   `json:"data,omitempty"`
+	Fields []*rawproto.Field `json:"fields,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// runInspect 解析浏览器开发者工具中复制的十六进制、base64 或文件数据
+func runInspect(args []string) {
+	fs := pflag.NewFlagSet("inspect", pflag.ExitOnError)
+	file := fs.StringP("file", "f", "", "从文件读取，- 表示标准输入")
+	method := fs.StringP("method", "m", "", "按指定方法解析 payload，如 WebcastChatMessage")
+	raw := fs.Bool("raw", false, "不识别类型，直接输出字段树")
+	_ = fs.Parse(args)
+
+	var input []byte
+	var err error
+	switch {
+	case *file == "-":
+		input, err = io.ReadAll(os.Stdin)
+	case *file != "":
+		input, err = os.ReadFile(*file)
+	case fs.NArg() > 0:
+		input = []byte(strings.Join(fs.Args(), ""))
+	default:
+		log.Fatalln("用法: douyinlive inspect [--method 方法名] [--raw] <hex|base64> | --file <路径>")
+	}
+	if err != nil {
+		log.Fatalf("读取输入失败: %v", err)
+	}
+
+	result, err := inspect(decodeInput(input), *method, *raw)
+	if err != nil {
+		log.Fatalf("解析失败: %v", err)
+	}
+	out, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(out))
+}
+
+// decodeInput 将十六进制或 base64 文本还原为二进制，无法识别时按原始二进制处理
+func decodeInput(input []byte) []byte {
+	text := strings.Join(strings.Fields(string(input)), "")
+	text = strings.TrimPrefix(strings.TrimPrefix(text, "0x"), "0X")
+	if len(text)%2 == 0 && hexRegexp.MatchString(text) {
+		if data, err := hex.DecodeString(text); err == nil {
+			return data
+		}
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if data, err := enc.DecodeString(text); err == nil && len(data) > 0 {
+			return data
+		}
+	}
+	return input
+}
+
+// gunzip 解压 gzip 数据，不是 gzip 时返回 false
+func gunzip(data []byte) ([]byte, bool) {
+	if len(data) < 2 || data[0] != 0x1f || data[1] != 0x8b {
+		return nil, false
+	}
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, false
+	}
+	defer r.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+// strictUnmarshal 反序列化并要求没有未知字段，用于判断数据是否为该类型
+func strictUnmarshal(data []byte, msg proto.Message) bool {
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return false
+	}
+	return len(msg.ProtoReflect().GetUnknown()) == 0
+}
+
+// inspect 自动识别 PushFrame、gzip 压缩的 Response、Message 或单独的 payload
+func inspect(data []byte, method string, raw bool) (*inspectResult, error) {
+	result := &inspectResult{Kind: kindUnknown}
+	if out, ok := gunzip(data); ok {
+		result.Gzip = true
+		data = out
+	}
+	if raw {
+		fields, err := rawproto.Decode(data)
+		result.Fields = fields
+		return result, err
+	}
+	if method != "" {
+		result.Kind = kindPayload
+		result.Messages = []inspectMessage{inspectPayload(&douyin.Message{Method: method, Payload: data})}
+		return result, nil
+	}
+
+	frame := &douyin.PushFrame{}
+	if strictUnmarshal(data, frame) && frame.PayloadType != "" {
+		result.Kind = kindPushFrame
+		payload := frame.Payload
+		frame.Payload = nil
+		result.Frame, _ = protojson.Marshal(frame)
+		if out, ok := gunzip(payload); ok && utils.HasGzipEncoding(frame.HeadersList) {
+			result.Gzip = true
+			payload = out
+		}
+		response := &douyin.Response{}
+		if frame.PayloadType == "msg" && strictUnmarshal(payload, response) {
+			result.setResponse(response)
+			return result, nil
+		}
+		fields, err := rawproto.Decode(payload)
+		result.Fields = fields
+		return result, err
+	}
+
+	response := &douyin.Response{}
+	if strictUnmarshal(data, response) && len(response.MessagesList) > 0 {
+		result.Kind = kindResponse
+		result.setResponse(response)
+		return result, nil
+	}
+
+	message := &douyin.Message{}
+	if strictUnmarshal(data, message) && strings.HasPrefix(message.Method, "Webcast") {
+		result.Kind = kindMessage
+		result.Messages = []inspectMessage{inspectPayload(message)}
+		return result, nil
+	}
+
+	fields, err := rawproto.Decode(data)
+	if err != nil {
+		return nil, errors.New("不是合法的 protobuf 数据: " + err.Error())
+	}
+	result.Fields = fields
+	return result, nil
+}
+
+// setResponse 填充 Response 及其中每条消息的解析结果
+func (r *inspectResult) setResponse(response *douyin.Response) {
+	messages := response.MessagesList
+	response.MessagesList = nil
+	r.Response, _ = protojson.Marshal(response)
+	for _, message := range messages {
+		r.Messages = append(r.Messages, inspectPayload(message))
+	}
+}
+
+// inspectPayload 通过 MessageMap 解析 payload，未知方法或解析失败时输出字段树
+func inspectPayload(message *douyin.Message) inspectMessage {
+	result := inspectMessage{Method: message.Method, MsgId: message.MsgId}
+	msg, err := utils.MatchMethod(message.Method)
+	if err == nil {
+		if err = proto.Unmarshal(message.Payload, msg); err == nil {
+			result.Data, err = protojson.Marshal(msg)
+			if err == nil {
+				return result
+			}
+		}
+	}
+	result.Error = err.Error()
+	result.Fields, _ = rawproto.Decode(message.Payload)
+	return result
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"douyinlive/generated/douyin"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestInspectPushFrame(t *testing.T) {
+	chat, _ := proto.Marshal(&douyin.ChatMessage{User: &douyin.User{NickName: "观众"}, Content: "你好"})
+	response, _ := proto.Marshal(&douyin.Response{
+		MessagesList: []*douyin.Message{
+			{Method: "WebcastChatMessage", Payload: chat, MsgId: 1},
+			{Method: "WebcastNewMessage", Payload: []byte{0x08, 0x01}, MsgId: 2},
+		},
+		NeedAck: true,
+	})
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	_, _ = w.Write(response)
+	_ = w.Close()
+	frame, _ := proto.Marshal(&douyin.PushFrame{
+		LogId:       1,
+		PayloadType: "msg",
+		HeadersList: []*douyin.HeadersList{{Key: "compress_type", Value: "gzip"}},
+		Payload:     compressed.Bytes(),
+	})
+
+	result, err := inspect(decodeInput([]byte(base64.StdEncoding.EncodeToString(frame))), "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Kind != kindPushFrame || !result.Gzip || len(result.Messages) != 2 {
+		t.Fatalf("识别结果错误: %+v", result)
+	}
+	if result.Messages[0].Data == nil || result.Messages[1].Fields == nil {
+		t.Fatalf("消息解析错误: %+v", result.Messages)
+	}
+}
+
+func TestInspectMessage(t *testing.T) {
+	like, _ := proto.Marshal(&douyin.LikeMessage{Count: 3, Total: 100})
+	message, _ := proto.Marshal(&douyin.Message{Method: "WebcastLikeMessage", Payload: like})
+
+	result, err := inspect(decodeInput([]byte(hex.EncodeToString(message))), "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Kind != kindMessage || len(result.Messages) != 1 || result.Messages[0].Data == nil {
+		t.Fatalf("识别结果错误: %+v", result)
+	}
+}
@@ -1,67 +1,201 @@
 package main
 
 import (
+	"context"
 	"douyinlive"
+	"douyinlive/auth"
+	"douyinlive/capture"
 	"douyinlive/config"
 	"douyinlive/database"
-	"douyinlive/generated/douyin"
-	"douyinlive/utils"
+	"douyinlive/dynproto"
+	"douyinlive/metrics"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
 	"strconv"
 	"sync"
+	"syscall"
 
 	"github.com/gorilla/websocket"
 	"github.com/spf13/pflag"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
 var (
 	agentlist sync.Map
 	unknown   bool
+	recordDir string
 )
 
 type LiveParam struct {
-	RoomId int    `json:"room_id"`
-	LiveId int    `json:"live_id"`
-	Ping   string `json:"ping"`
+	Action    string   `json:"action"`     // start、subscribe 或 unsubscribe，为空时视为 start
+	Methods   []string `json:"methods"`    // 订阅的消息类型，可省略 Webcast 前缀，为空时订阅全部
+	WebRid    string   `json:"web_rid"`    // 直播间链接中的 ID
+	Room      string   `json:"room"`       // 直播间链接、分享短链或主页链接，未传 web_rid 时解析得到
+	SessionId int      `json:"session_id"` // 场次 ID，不传时由服务分配并在通知中返回
+	Ping      string   `json:"ping"`
+	Record    bool     `json:"record"`
+
+	// 已废弃：旧协议中 room_id 实际为 web_rid，容易与 webcast room_id 混淆，传入时直接报错
+	LegacyRoomId json.RawMessage `json:"room_id"`
+	// 已废弃：旧协议中 live_id 即 session_id
+	LegacyLiveId int `json:"live_id"`
 }
 
+// errLegacyRoomId 请求中使用了已废弃的 room_id
+var errLegacyRoomId = errors.New("room_id 已废弃，请改用 web_rid（直播间链接中的 ID）或 room")
+
 type responseData struct {
-	RoomId int `json:"room_id"`
-	Status int `json:"status"`
+	WebRid        string `json:"web_rid"`
+	LegacyRoomId  int    `json:"room_id,omitempty"` // 兼容旧协议，值与 web_rid 相同
+	WebcastRoomId string `json:"webcast_room_id"`   // 抖音内部的 webcast room_id
+	SessionId     int    `json:"session_id"`
+	Status        int    `json:"status"`
+}
+
+// newResponseData 生成通知数据，room_id 沿用旧协议的含义填写 web_rid
+func newResponseData(webRid douyinlive.WebRid, roomID douyinlive.RoomID, session douyinlive.SessionID, status int) responseData {
+	legacy, _ := strconv.Atoi(string(webRid))
+	return responseData{
+		WebRid:        string(webRid),
+		LegacyRoomId:  legacy,
+		WebcastRoomId: string(roomID),
+		SessionId:     int(session),
+		Status:        status,
+	}
+}
+
+// webRid 解析请求中的直播间，旧协议的 room_id 不再接受
+func (p *LiveParam) webRid() (douyinlive.WebRid, error) {
+	switch {
+	case len(p.LegacyRoomId) > 0:
+		return "", errLegacyRoomId
+	case p.WebRid != "":
+		return douyinlive.ResolveWebRid(p.WebRid)
+	case p.Room != "":
+		return douyinlive.ResolveWebRid(p.Room)
+	}
+	return "", nil
+}
+
+// session 请求中的场次 ID，兼容旧协议的 live_id
+func (p *LiveParam) session() douyinlive.SessionID {
+	if p.SessionId != 0 {
+		return douyinlive.SessionID(p.SessionId)
+	}
+	return douyinlive.SessionID(p.LegacyLiveId)
+}
+
+// roomData 直播间当前的标识，用于通知客户端
+func roomData(d *douyinlive.DouyinLive, status int) responseData {
+	return newResponseData(d.WebRid(), d.RoomID(), d.Session(), status)
 }
 
 func main() {
-	var port string
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "unknowns":
+			runUnknowns(os.Args[2:])
+			return
+		case "inspect":
+			runInspect(os.Args[2:])
+			return
+		case "watch":
+			runWatch(os.Args[2:])
+			return
+		}
+	}
+
 	var room string
-	pflag.StringVar(&port, "port", "18080", "WebSocket 服务端口")
+	var unknownDir string
+	var flags serverFlags
+	flags.register(pflag.CommandLine)
 	pflag.StringVar(&room, "room", "****", "抖音直播房间号")
 	pflag.BoolVar(&unknown, "unknown", false, "是否输出未知源的pb消息")
+	pflag.StringVar(&unknownDir, "unknown-dir", "unknown", "未知消息存储目录")
+	pflag.StringVar(&recordDir, "record-dir", "records", "原始帧录制目录")
+	_ = pflag.CommandLine.MarkDeprecated("room", "请使用 douyinlive watch <web_rid>")
 	pflag.Parse()
 
+	if unknown {
+		var err error
+		unknownStore, err = capture.Open(unknownDir)
+		if err != nil {
+			log.Fatalf("打开未知消息存储失败: %v", err)
+		}
+	}
+
 	//加载配置配置文件
 	config.Init()
-	database.InitRMSDB(config.Conf.DbConf)
+	config.Conf.StorageConf.LoadEnv()
+	storageConf := config.Conf.StorageConf.Resolve(config.Conf.DbConf)
+	if err := database.Init(storageConf, config.Conf.DbConf); err != nil && database.Enabled() {
+		log.Printf("连接数据库失败，以降级模式运行，数据库恢复后自动重连: %v\n", err)
+	} else if err != nil {
+		log.Printf("连接数据库失败，以降级模式运行，数据不会入库: %v\n", err)
+	} else if storageConf.Driver == config.StorageNone {
+		log.Println("未配置存储，数据不会入库")
+	}
+	serverConf = flags.apply(pflag.CommandLine, config.Conf.ServerConf)
+	config.Conf.AuthConf.LoadEnv()
+	authenticator = auth.New(config.Conf.AuthConf)
+	if !authenticator.Enabled() {
+		log.Println("未配置 API Key 与 JWT 密钥，接口不做鉴权")
+	}
+
+	// 加载运行时 .proto，注册额外的消息类型
+	n, err := dynproto.Load(config.Conf.ProtoConf)
+	if err != nil {
+		log.Fatalf("加载 .proto 失败: %v", err)
+	}
+	if n > 0 {
+		log.Printf("已从 .proto 注册 %d 个消息类型\n", n)
+	}
+
+	metrics.SetPerRoom(config.Conf.MetricsConf.PerRoom)
+
+	forwardOptions = protojson.MarshalOptions{
+		UseProtoNames:   config.Conf.ForwardConf.UseProtoNames,
+		EmitUnpopulated: config.Conf.ForwardConf.EmitUnpopulated,
+		UseEnumNumbers:  config.Conf.ForwardConf.UseEnumNumbers,
+	}
+
+	// 收到 SIGINT/SIGTERM 后优雅关闭
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 创建或更新表结构，数据库暂时不可用时在后台重试
+	migrateSchema(ctx)
+
+	// 加载主播关注列表，开播后自动抓取，关闭服务时停止检查
+	if err := startWatchlist(ctx, config.Conf.WatchConf); err != nil {
+		log.Fatalf("加载主播关注列表失败: %v", err)
+	}
 
 	// 创建 WebSocket 升级器
 	upgrader := websocket.Upgrader{
-		CheckOrigin: func(r *http.Request) bool {
-			return true // 允许所有 CORS 请求，实际应用中应根据需要设置
-		},
+		CheckOrigin: checkOrigin,
 	}
 
 	// 设置 WebSocket 路由
 	http.HandleFunc("/ws/start", func(w http.ResponseWriter, r *http.Request) {
+		// 升级前鉴权，浏览器客户端通过 token 参数传递凭证
+		principal := authenticate(w, r, auth.ScopeRead)
+		if principal == nil {
+			return
+		}
 		conn, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
 			log.Printf("升级 WebSocket 失败: %v\n", err)
 			return
 		}
-		defer conn.Close()
-
 		sec := r.Header.Get("Sec-WebSocket-Key")
-		StoreConnection(sec, conn)
+		c := NewClient(sec, conn, principal)
+		StoreConnection(sec, c)
 		log.Printf("当前连接数: %d\n", GetConnectionCount())
 
 		defer func() {
@@ -71,110 +205,155 @@ func main() {
 		}()
 
 		// 处理 WebSocket 消息
-		for {
-			_, message, err := conn.ReadMessage()
-			if err != nil {
-				log.Printf("读取消息失败: %v\n", err)
-				break
-			}
-
+		c.Serve(func(message []byte) error {
 			if string(message) == "ping" {
 				pong, _ := json.Marshal("pong")
-				if err := conn.WriteMessage(websocket.TextMessage, pong); err != nil {
+				if err := c.Send(pong); err != nil {
 					log.Printf("发送心跳回应到客户端失败: %v\n", err)
 				}
-				continue
+				return nil
 			}
 
 			var liveParam LiveParam
-			err = json.Unmarshal(message, &liveParam)
+			if err := json.Unmarshal(message, &liveParam); err != nil {
+				return fmt.Errorf("消息解析失败: %w", err)
+			}
+
+			webRid, err := liveParam.webRid()
 			if err != nil {
-				log.Printf("消息解析失败: %v\n", err)
-				break
+				log.Printf("解析直播间失败: %v\n", err)
+				if err := c.reply(false, err.Error(), nil); err != nil {
+					log.Printf("发送消息到客户端失败: %v\n", err)
+				}
+				return nil
+			}
+
+			if webRid == "" {
+				return nil
 			}
 
-			if liveParam.RoomId != 0 && liveParam.LiveId != 0 {
-				isLiving := utils.InSlice(douyinlive.LivingRoomIds, liveParam.RoomId)
-				// 如果room id没有在抓取弹幕信息，继续执行
-				if !isLiving {
-					douyinlive.LivingRoomIds = append(douyinlive.LivingRoomIds, liveParam.RoomId)
-					go func() {
-						// 创建 DouyinLive 实例
-						d, err := douyinlive.NewDouyinLive(strconv.Itoa(liveParam.RoomId))
-						if err != nil {
-							log.Fatalf("抖音链接失败: %v", err)
-						}
-						// 订阅事件
-						d.Subscribe(Subscribe)
-						// 开始处理
-						d.Start(liveParam.RoomId, liveParam.LiveId)
-					}()
-				} else {
+			switch liveParam.Action {
+			case actionSubscribe:
+				c.subscribe(webRid, liveParam.Methods)
+				if err := c.reply(true, "subscribed", c.subscriptions()); err != nil {
+					log.Printf("发送消息到客户端失败: %v\n", err)
+				}
+			case actionUnsubscribe:
+				c.unsubscribe(webRid)
+				if err := c.reply(true, "unsubscribed", c.subscriptions()); err != nil {
+					log.Printf("发送消息到客户端失败: %v\n", err)
+				}
+			case actionStart, "":
+				if !c.principal.Has(auth.ScopeStart) {
+					if err := c.reply(false, "缺少权限: "+auth.ScopeStart, nil); err != nil {
+						log.Printf("发送消息到客户端失败: %v\n", err)
+					}
+					return nil
+				}
+				// 开始抓取的客户端自动订阅该直播间
+				c.subscribe(webRid, liveParam.Methods)
+				// 如果直播间没有在抓取弹幕信息，开始抓取
+				err := launchRoom(webRid, liveParam.session(), liveParam.Record, c.principal.Name)
+				switch {
+				case err == nil:
+				case !errors.Is(err, errRoomLiving):
+					if err := c.reply(false, err.Error(), nil); err != nil {
+						log.Printf("发送消息到客户端失败: %v\n", err)
+					}
+				default:
+					data := newResponseData(webRid, "", 0, 3)
+					if d := liveRoom(webRid); d != nil {
+						data = roomData(d, 3)
+					}
 					livingNotificationMap := map[string]interface{}{
 						"is_ok": true,
-						"data": responseData{
-							Status: 3,
-							RoomId: liveParam.RoomId,
-						},
+						"data":  data,
 					}
 					livingNotification, _ := json.Marshal(livingNotificationMap)
-					if err := conn.WriteMessage(websocket.TextMessage, livingNotification); err != nil {
+					if err := c.Send(livingNotification); err != nil {
 						log.Printf("发送消息到客户端失败: %v\n", err)
 					}
-					log.Printf("room id %v 已在抓取弹幕信息\n", liveParam.RoomId)
+					log.Printf("直播间 %v 已在抓取弹幕信息\n", webRid)
+				}
+			default:
+				if err := c.reply(false, "未知指令: "+liveParam.Action, nil); err != nil {
+					log.Printf("发送消息到客户端失败: %v\n", err)
 				}
 			}
-		}
+			return nil
+		})
 	})
 
-	http.HandleFunc("/api/stop", func(w http.ResponseWriter, r *http.Request) {
-		roomIdStr := r.URL.Query().Get("room_id")
-		roomId, _ := strconv.Atoi(roomIdStr)
-
-		// 判断该room id是否正在抓取弹幕
-		isLiving := utils.InSlice(douyinlive.LivingRoomIds, roomId)
+	http.HandleFunc("/api/stop", requireScope(auth.ScopeStop, func(w http.ResponseWriter, r *http.Request) {
+		webRid, err := queryWebRid(r)
+		if err != nil {
+			jsonResponse, _ := json.Marshal(map[string]interface{}{"is_ok": false, "message": err.Error()})
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write(jsonResponse)
+			return
+		}
 
 		responseData := map[string]interface{}{
 			"is_ok":   false,
 			"message": "room id 并未在抓取弹幕信息",
 		}
-		if isLiving == true {
-			if douyinlive.Close(roomId) {
-				responseData = map[string]interface{}{
-					"is_ok":   true,
-					"message": "success",
-				}
-			} else {
-				responseData = map[string]interface{}{
-					"is_ok":   false,
-					"message": "room id正在执行解析消息",
-				}
+		switch stopRoom(webRid) {
+		case codeStopTimeout:
+			responseData = map[string]interface{}{
+				"is_ok":   false,
+				"message": "room id 已通知停止，等待退出超时",
+			}
+		case "stopped":
+			responseData = map[string]interface{}{
+				"is_ok":   true,
+				"message": "success",
 			}
 		}
 		// 将数据编码为 JSON 格式
 		jsonResponse, _ := json.Marshal(responseData)
 		w.Write(jsonResponse)
-	})
+	}))
+
+	http.HandleFunc("/api/record", requireScope(auth.ScopeStart, handleRecord))
+	http.HandleFunc("GET /api/watchlist", requireScope(auth.ScopeRead, handleWatchlist))
+	http.HandleFunc("POST /api/watchlist", requireScope(auth.ScopeStart, handleWatchlist))
+	http.HandleFunc("DELETE /api/watchlist", requireScope(auth.ScopeStop, handleWatchlist))
+	http.HandleFunc("POST /api/rooms", requireScope(auth.ScopeStart, handleCreateRoom))
+	http.HandleFunc("GET /api/rooms", requireScope(auth.ScopeRead, handleListRooms))
+	http.HandleFunc("POST /api/rooms/stop", requireScope(auth.ScopeStop, handleBulkStopRooms))
+	http.HandleFunc("GET /api/rooms/{id}", requireScope(auth.ScopeRead, handleGetRoom))
+	http.HandleFunc("DELETE /api/rooms/{id}", requireScope(auth.ScopeStop, handleDeleteRoom))
+	http.HandleFunc("GET /metrics", requireScope(auth.ScopeRead, metrics.Handler().ServeHTTP))
+	// 负载均衡探活，不需要鉴权
+	http.HandleFunc("GET /healthz", handleHealthz)
+	http.HandleFunc("GET /readyz", handleReadyz)
 
-	// 启动 WebSocket 服务器
-	http.ListenAndServe(":18080", corsMiddleware(http.DefaultServeMux))
-	log.Printf("WebSocket 服务启动成功，地址为: ws://127.0.0.1:18080/\n")
+	// 启动 WebSocket 服务器，收到 SIGINT/SIGTERM 后优雅关闭
+	server := newServer(serverConf, corsMiddleware(http.DefaultServeMux))
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- listenAndServe(server, serverConf)
+	}()
+	select {
+	case err := <-serveErr:
+		log.Fatalf("WebSocket 服务启动失败: %v", err)
+	case <-ctx.Done():
+	}
+	stop()
+	shutdown(server)
 }
 
 // Subscribe 处理订阅的更新
-func Subscribe(eventData *douyin.Message) {
+func Subscribe(eventData *douyinlive.Event) {
 	//关闭通知
 	if eventData.Method == "OffNotification" {
 		offNotificationMap := map[string]interface{}{
 			"is_ok": true,
-			"data": responseData{
-				Status: 1,
-				RoomId: eventData.RoomId,
-			},
+			"data":  notificationData(eventData, 1),
 		}
 		offNotification, _ := json.Marshal(offNotificationMap)
-		RangeConnections(func(agentID string, conn *websocket.Conn) {
-			if err := conn.WriteMessage(websocket.TextMessage, offNotification); err != nil {
+		RangeSubscribers(eventData, func(agentID string, c *Client) {
+			if err := c.Send(offNotification); err != nil {
 				log.Printf("发送消息到客户端 %s 失败: %v\n", agentID, err)
 			}
 		})
@@ -183,14 +362,11 @@ func Subscribe(eventData *douyin.Message) {
 	if eventData.Method == "ErrNotification" {
 		errNotificationMap := map[string]interface{}{
 			"is_ok": false,
-			"data": responseData{
-				Status: 1,
-				RoomId: eventData.RoomId,
-			},
+			"data":  notificationData(eventData, 1),
 		}
 		errNotification, _ := json.Marshal(errNotificationMap)
-		RangeConnections(func(agentID string, conn *websocket.Conn) {
-			if err := conn.WriteMessage(websocket.TextMessage, errNotification); err != nil {
+		RangeSubscribers(eventData, func(agentID string, c *Client) {
+			if err := c.Send(errNotification); err != nil {
 				log.Printf("发送消息到客户端 %s 失败: %v\n", agentID, err)
 			}
 		})
@@ -199,50 +375,29 @@ func Subscribe(eventData *douyin.Message) {
 	if eventData.Method == "SuccessNotification" {
 		successNotificationMap := map[string]interface{}{
 			"is_ok": true,
-			"data": responseData{
-				Status: 0,
-				RoomId: eventData.RoomId,
-			},
+			"data":  notificationData(eventData, 0),
 		}
 		successNotification, _ := json.Marshal(successNotificationMap)
-		RangeConnections(func(agentID string, conn *websocket.Conn) {
-			if err := conn.WriteMessage(websocket.TextMessage, successNotification); err != nil {
+		RangeSubscribers(eventData, func(agentID string, c *Client) {
+			if err := c.Send(successNotification); err != nil {
 				log.Printf("发送消息到客户端 %s 失败: %v\n", agentID, err)
 			}
 		})
 	}
 
-	//msg, err := utils.MatchMethod(eventData.Method)
-	//if err != nil {
-	//	if unknown {
-	//		log.Printf("未知消息，无法处理: %v, %s\n", err, hex.EncodeToString(eventData.Payload))
-	//	}
-	//	return
-	//}
-	//
-	//if msg != nil {
-	//	if err := proto.Unmarshal(eventData.Payload, msg); err != nil {
-	//		log.Printf("反序列化失败: %v, 方法: %s\n", err, eventData.Method)
-	//		return
-	//	}
-	//
-	//	marshal, err := protojson.Marshal(msg)
-	//	if err != nil {
-	//		log.Printf("JSON 序列化失败: %v\n", err)
-	//		return
-	//	}
-	//
-	//	RangeConnections(func(agentID string, conn *websocket.Conn) {
-	//		if err := conn.WriteMessage(websocket.TextMessage, marshal); err != nil {
-	//			log.Printf("发送消息到客户端 %s 失败: %v\n", agentID, err)
-	//		}
-	//	})
-	//}
+	captureUnknown(eventData)
+
+	forwardEvent(eventData)
+}
+
+// notificationData 通知中的直播间标识
+func notificationData(eventData *douyinlive.Event, status int) responseData {
+	return newResponseData(eventData.WebRid, eventData.RoomID, eventData.Session, status)
 }
 
 // StoreConnection 储存 WebSocket 客户端连接
-func StoreConnection(agentID string, conn *websocket.Conn) {
-	agentlist.Store(agentID, conn)
+func StoreConnection(agentID string, c *Client) {
+	agentlist.Store(agentID, c)
 }
 
 // DeleteConnection 删除 WebSocket 客户端连接
@@ -251,21 +406,30 @@ func DeleteConnection(agentID string) {
 }
 
 // RangeConnections 遍历 WebSocket 客户端连接
-func RangeConnections(f func(agentID string, conn *websocket.Conn)) {
+func RangeConnections(f func(agentID string, c *Client)) {
 	agentlist.Range(func(key, value interface{}) bool {
 		agentID, ok := key.(string)
 		if !ok {
 			return true // 跳过错误的键类型
 		}
-		conn, ok := value.(*websocket.Conn)
+		c, ok := value.(*Client)
 		if !ok {
 			return true // 跳过错误的值类型
 		}
-		f(agentID, conn)
+		f(agentID, c)
 		return true
 	})
 }
 
+// RangeSubscribers 遍历订阅了该消息的客户端连接
+func RangeSubscribers(eventData *douyinlive.Event, f func(agentID string, c *Client)) {
+	RangeConnections(func(agentID string, c *Client) {
+		if c.wants(eventData) {
+			f(agentID, c)
+		}
+	})
+}
+
 // GetConnectionCount 获取当前连接数
 func GetConnectionCount() int {
 	count := 0
@@ -279,10 +443,25 @@ func GetConnectionCount() int {
 // corsMiddleware 返回一个处理 CORS 请求的中间件
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// 设置允许的源，这里使用通配符 * 表示允许所有源
-		w.Header().Set("Access-Control-Allow-Origin", "*")
+		// 只为允许的来源设置 CORS 头，未配置来源时允许所有源
+		origin := r.Header.Get("Origin")
+		allowed := originAllowed(origin)
+		if allowed {
+			if len(serverConf.AllowedOrigins) == 0 || origin == "" {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Add("Vary", "Origin")
+			}
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key")
+		}
 
 		if r.Method == "OPTIONS" {
+			if !allowed {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
 			w.WriteHeader(http.StatusNoContent)
 			return
 		}
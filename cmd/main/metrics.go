@@ -0,0 +1,46 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// 重新开始抓取的来源
+const (
+	reconnectRoom      = "room"      // 客户端或接口再次开始抓取
+	reconnectWatchlist = "watchlist" // 关注列表在主播再次开播或连接断开后重新开始抓取
+)
+
+// 服务端指标，直播间与连接数在采集时读取
+var (
+	_ = promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "douyinlive_rooms_active",
+		Help: "正在抓取的直播间数",
+	}, func() float64 {
+		return float64(len(livingRooms()))
+	})
+	_ = promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "douyinlive_ws_clients",
+		Help: "WebSocket 客户端连接数",
+	}, func() float64 {
+		return float64(GetConnectionCount())
+	})
+	_ = promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "douyinlive_dispatch_queue_depth",
+		Help: "所有客户端待发送队列中的消息数",
+	}, func() float64 {
+		depth := 0
+		RangeConnections(func(agentID string, c *Client) {
+			depth += len(c.out)
+		})
+		return float64(depth)
+	})
+	dispatchDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "douyinlive_dispatch_dropped_total",
+		Help: "客户端发送队列已满而丢弃的消息数",
+	})
+	reconnects = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "douyinlive_reconnects_total",
+		Help: "直播间退出后在 reconnectWindow 内重新开始抓取的次数，source 为 room 或 watchlist",
+	}, []string{"source"})
+)
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"douyinlive"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// startRecording 开始录制直播间的原始帧，文件保存在 recordDir 下
+func startRecording(d *douyinlive.DouyinLive) error {
+	if err := os.MkdirAll(recordDir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.dyfa", d.WebRid(), time.Now().Format("20060102-150405"))
+	return d.StartRecording(filepath.Join(recordDir, name))
+}
+
+// handleRecord 开启或关闭直播间录制：/api/record?web_rid=xxx&enable=1
+func handleRecord(w http.ResponseWriter, r *http.Request) {
+	webRid, err := queryWebRid(r)
+	if err != nil {
+		jsonResponse, _ := json.Marshal(map[string]interface{}{"is_ok": false, "message": err.Error()})
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write(jsonResponse)
+		return
+	}
+	enable := r.URL.Query().Get("enable") == "1"
+
+	responseData := map[string]interface{}{
+		"is_ok":   false,
+		"message": "room id 并未在抓取弹幕信息",
+	}
+	if d := liveRoom(webRid); d != nil {
+		var err error
+		if enable {
+			err = startRecording(d)
+		} else {
+			err = d.StopRecording()
+		}
+		if err != nil {
+			responseData["message"] = err.Error()
+		} else {
+			responseData = map[string]interface{}{
+				"is_ok":     true,
+				"message":   "success",
+				"recording": d.Recording(),
+			}
+		}
+	}
+	jsonResponse, _ := json.Marshal(responseData)
+	w.Write(jsonResponse)
+}
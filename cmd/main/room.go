@@ -0,0 +1,342 @@
+package main
+
+import (
+	"context"
+	"douyinlive"
+	"douyinlive/generated/douyin"
+	"douyinlive/metrics"
+	"douyinlive/model"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// sessionSeq 未连接数据库或记录场次失败时分配场次 ID 的序号
+var sessionSeq atomic.Int64
+
+// stopWait 停止直播间时等待其退出的时间
+const stopWait = 5 * time.Second
+
+// reconnectWindow 直播间退出后在该时间内再次开始抓取时计为一次重连
+const reconnectWindow = 30 * time.Minute
+
+// failureTTL 开始抓取失败的记录保留时间，期间可通过 GET /api/rooms/{id} 查询失败原因
+const failureTTL = 10 * time.Minute
+
+var (
+	// errShuttingDown 服务正在关闭，不再开始新的直播间
+	errShuttingDown = errors.New("服务正在关闭，不再接受新的直播间")
+	// errRoomsFull 正在抓取的直播间已达到 MaxRooms
+	errRoomsFull = errors.New("正在抓取的直播间已达上限")
+	// errRoomLiving 直播间已在抓取
+	errRoomLiving = errors.New("直播间已在抓取弹幕信息")
+)
+
+// roomHandle 一个正在抓取的直播间
+type roomHandle struct {
+	webRid     douyinlive.WebRid
+	startedBy  string // 开始抓取的调用方，用于审计
+	launchedAt time.Time
+	cancel     context.CancelFunc
+	done       chan struct{}          // 抓取协程退出后关闭
+	live       *douyinlive.DouyinLive // 连接建立前为 nil，由 roomsMu 保护
+}
+
+// roomFailure 直播间最近一次开始抓取失败的记录
+type roomFailure struct {
+	startedBy string
+	err       string
+	at        time.Time
+}
+
+var (
+	// roomsMu 保护正在抓取的直播间集合、失败记录、roomsClosed 与 roomsWG.Add
+	roomsMu  sync.Mutex
+	living   = make(map[douyinlive.WebRid]*roomHandle)
+	failures = make(map[douyinlive.WebRid]roomFailure)
+	// ended 直播间最近一次退出的时间，用于统计重连，超过 reconnectWindow 的会被清理
+	ended       = make(map[douyinlive.WebRid]time.Time)
+	roomsClosed bool
+	roomsWG     sync.WaitGroup
+	// roomsCtx 所有直播间的父 context，关闭服务时取消
+	roomsCtx, cancelRooms = context.WithCancel(context.Background())
+)
+
+// launchRoom 检查并标记直播间正在抓取，在新协程中开始抓取，startedBy 为发起抓取的调用方
+//
+// 检查与标记在同一把锁内完成，并发开始同一直播间时只有一个成功，其余返回 errRoomLiving
+func launchRoom(webRid douyinlive.WebRid, session douyinlive.SessionID, record bool, startedBy string) error {
+	roomsMu.Lock()
+	defer roomsMu.Unlock()
+	if roomsClosed {
+		return errShuttingDown
+	}
+	if _, ok := living[webRid]; ok {
+		return errRoomLiving
+	}
+	if saturatedLocked() {
+		return errRoomsFull
+	}
+	ctx, cancel := context.WithCancel(roomsCtx)
+	handle := &roomHandle{
+		webRid:     webRid,
+		startedBy:  startedBy,
+		launchedAt: time.Now(),
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+	living[webRid] = handle
+	delete(failures, webRid)
+	countReconnectLocked(handle)
+	log.Printf("%s 开始抓取直播间 %s\n", startedBy, webRid)
+	roomsWG.Add(1)
+	go func() {
+		defer roomsWG.Done()
+		defer close(handle.done)
+		defer removeRoom(handle)
+		defer cancel()
+		startRoom(ctx, handle, session, record)
+	}()
+	return nil
+}
+
+// countReconnectLocked 直播间退出后不久再次开始抓取时计为一次重连，调用方需持有 roomsMu
+func countReconnectLocked(handle *roomHandle) {
+	at, ok := ended[handle.webRid]
+	if !ok {
+		return
+	}
+	delete(ended, handle.webRid)
+	if handle.launchedAt.Sub(at) > reconnectWindow {
+		return
+	}
+	source := reconnectRoom
+	if handle.startedBy == watchlistPrincipal {
+		source = reconnectWatchlist
+	}
+	reconnects.WithLabelValues(source).Inc()
+}
+
+// removeRoom 抓取协程退出后移除直播间，并删除其按直播间区分的指标序列
+func removeRoom(handle *roomHandle) {
+	roomsMu.Lock()
+	defer roomsMu.Unlock()
+	if living[handle.webRid] != handle {
+		return
+	}
+	delete(living, handle.webRid)
+	now := time.Now()
+	for webRid, at := range ended {
+		if now.Sub(at) > reconnectWindow {
+			delete(ended, webRid)
+		}
+	}
+	ended[handle.webRid] = now
+	metrics.DeleteRoom(string(handle.webRid))
+}
+
+// failRoom 记录开始抓取失败，并通知开始抓取的客户端与该直播间的订阅者
+func failRoom(handle *roomHandle, err error) {
+	now := time.Now()
+	roomsMu.Lock()
+	for webRid, failure := range failures {
+		if now.Sub(failure.at) > failureTTL {
+			delete(failures, webRid)
+		}
+	}
+	failures[handle.webRid] = roomFailure{startedBy: handle.startedBy, err: err.Error(), at: now}
+	roomsMu.Unlock()
+
+	errNotification, _ := json.Marshal(map[string]interface{}{
+		"is_ok":   false,
+		"message": "抖音链接失败: " + err.Error(),
+		"data":    newResponseData(handle.webRid, "", 0, 1),
+	})
+	event := &douyinlive.Event{Message: &douyin.Message{Method: "ErrNotification"}, WebRid: handle.webRid}
+	RangeSubscribers(event, func(agentID string, c *Client) {
+		if err := c.Send(errNotification); err != nil {
+			log.Printf("发送消息到客户端 %s 失败: %v\n", agentID, err)
+		}
+	})
+}
+
+// lastFailure 直播间最近一次开始抓取失败的记录，超过 failureTTL 的不再返回
+func lastFailure(webRid douyinlive.WebRid) (roomFailure, bool) {
+	roomsMu.Lock()
+	defer roomsMu.Unlock()
+	failure, ok := failures[webRid]
+	if !ok || time.Since(failure.at) > failureTTL {
+		return roomFailure{}, false
+	}
+	return failure, true
+}
+
+// publish 连接建立前公开 DouyinLive，之后可通过 liveRoom 查询
+func (h *roomHandle) publish(d *douyinlive.DouyinLive) {
+	roomsMu.Lock()
+	defer roomsMu.Unlock()
+	h.live = d
+}
+
+// lookupRoom 正在抓取的直播间
+func lookupRoom(webRid douyinlive.WebRid) (*roomHandle, bool) {
+	roomsMu.Lock()
+	defer roomsMu.Unlock()
+	handle, ok := living[webRid]
+	return handle, ok
+}
+
+// liveRoom 正在抓取的直播间的 DouyinLive，未在抓取或连接尚未建立时为 nil
+func liveRoom(webRid douyinlive.WebRid) *douyinlive.DouyinLive {
+	roomsMu.Lock()
+	defer roomsMu.Unlock()
+	if handle, ok := living[webRid]; ok {
+		return handle.live
+	}
+	return nil
+}
+
+// isLiving 直播间是否正在抓取
+func isLiving(webRid douyinlive.WebRid) bool {
+	_, ok := lookupRoom(webRid)
+	return ok
+}
+
+// livingRooms 正在抓取的直播间，按开始抓取的时间排序
+func livingRooms() []douyinlive.WebRid {
+	roomsMu.Lock()
+	handles := make([]*roomHandle, 0, len(living))
+	for _, handle := range living {
+		handles = append(handles, handle)
+	}
+	roomsMu.Unlock()
+	sort.Slice(handles, func(i, j int) bool { return handles[i].launchedAt.Before(handles[j].launchedAt) })
+	webRids := make([]douyinlive.WebRid, len(handles))
+	for i, handle := range handles {
+		webRids[i] = handle.webRid
+	}
+	return webRids
+}
+
+// roomsSaturated 正在抓取的直播间是否已达到上限
+func roomsSaturated() bool {
+	roomsMu.Lock()
+	defer roomsMu.Unlock()
+	return saturatedLocked()
+}
+
+func saturatedLocked() bool {
+	return serverConf.MaxRooms > 0 && len(living) >= serverConf.MaxRooms
+}
+
+// acceptingRooms 服务是否仍接受新的直播间
+func acceptingRooms() bool {
+	roomsMu.Lock()
+	defer roomsMu.Unlock()
+	return !roomsClosed
+}
+
+// roomOwner 开始抓取直播间的调用方
+func roomOwner(webRid douyinlive.WebRid) string {
+	if handle, ok := lookupRoom(webRid); ok {
+		return handle.startedBy
+	}
+	return ""
+}
+
+// stopRoom 停止抓取直播间并等待其退出，返回 stopped 或错误码
+func stopRoom(webRid douyinlive.WebRid) string {
+	handle, ok := lookupRoom(webRid)
+	if !ok {
+		return codeRoomNotFound
+	}
+	handle.cancel()
+	select {
+	case <-handle.done:
+		return "stopped"
+	case <-time.After(stopWait):
+		return codeStopTimeout
+	}
+}
+
+// stopAllRooms 不再开始新的直播间，取消所有直播间并等待退出，ctx 到期时返回其错误
+func stopAllRooms(ctx context.Context) error {
+	roomsMu.Lock()
+	roomsClosed = true
+	roomsMu.Unlock()
+	cancelRooms()
+
+	done := make(chan struct{})
+	go func() {
+		roomsWG.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// startRoom 连接直播间并阻塞处理消息直到 ctx 取消，session 为 0 时由服务分配场次 ID
+func startRoom(ctx context.Context, handle *roomHandle, session douyinlive.SessionID, record bool) {
+	webRid := handle.webRid
+	// 创建 DouyinLive 实例
+	d, err := douyinlive.NewDouyinLive(string(webRid))
+	if err != nil {
+		log.Printf("抖音链接失败: %v\n", err)
+		failRoom(handle, err)
+		return
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	if session == 0 {
+		session = allocateSession(d, handle.startedBy)
+	}
+	// 订阅事件
+	d.Subscribe(Subscribe)
+	if record {
+		if err := startRecording(d); err != nil {
+			log.Printf("直播间 %s 开始录制失败: %v\n", webRid, err)
+		}
+	}
+	handle.publish(d)
+	// 开始处理
+	d.Start(ctx, session)
+	if err := model.FinishLiveSession(int(session)); err != nil {
+		log.Printf("记录场次 %d 结束时间失败: %v\n", session, err)
+	}
+}
+
+// allocateSession 分配场次 ID，连接数据库时使用 live_sessions 表的自增 ID，
+// 未连接数据库或写入失败时使用进程内的序号，不影响抓取
+func allocateSession(d *douyinlive.DouyinLive, startedBy string) douyinlive.SessionID {
+	session := &model.LiveSession{
+		WebRid:    string(d.WebRid()),
+		RoomId:    string(d.RoomID()),
+		StartedBy: startedBy,
+	}
+	if err := model.CreateLiveSession(session); err != nil {
+		log.Printf("直播间 %s 记录场次失败，使用进程内序号: %v\n", d.WebRid(), err)
+	}
+	if session.Id != 0 {
+		return douyinlive.SessionID(session.Id)
+	}
+	return douyinlive.SessionID(sessionSeq.Add(1))
+}
+
+// queryWebRid 读取请求中的直播间，旧接口使用的 room_id 参数不再接受
+func queryWebRid(r *http.Request) (douyinlive.WebRid, error) {
+	query := r.URL.Query()
+	if query.Has("room_id") {
+		return "", errLegacyRoomId
+	}
+	return douyinlive.WebRid(query.Get("web_rid")), nil
+}
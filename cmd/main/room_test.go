@@ -0,0 +1,84 @@
+package main
+
+import (
+	"douyinlive"
+	"douyinlive/archive"
+	"douyinlive/database"
+	"douyinlive/metrics"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestAllocateSessionFallback(t *testing.T) {
+	// 未建表时写入场次失败，应回退到进程内序号而不是中止抓取
+	if err := database.InitSQLite(filepath.Join(t.TempDir(), "douyinlive.db")); err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	d := douyinlive.NewReplayLive(archive.Header{WebRid: "644826113301", RoomId: "7383731312643626035"})
+	first, second := allocateSession(d, "test"), allocateSession(d, "test")
+	if first == 0 || second != first+1 {
+		t.Fatalf("期望递增的进程内序号，得到 %d %d", first, second)
+	}
+}
+
+func TestNotificationKeepsLegacyRoomId(t *testing.T) {
+	data, _ := json.Marshal(newResponseData("644826113301", "7383731312643626035", 7, 0))
+	want := `{"web_rid":"644826113301","room_id":644826113301,"webcast_room_id":"7383731312643626035","session_id":7,"status":0}`
+	if string(data) != want {
+		t.Fatalf("通知数据 = %s", data)
+	}
+}
+
+var testRoomTotal = metrics.NewRoomCounterVec("douyinlive_test_room_total", "测试用的直播间计数器")
+
+func TestRoomReconnectMetrics(t *testing.T) {
+	metrics.SetPerRoom(true)
+	defer metrics.SetPerRoom(false)
+	d := douyinlive.NewReplayLive(archive.Header{WebRid: "10000000002"})
+	handle := &roomHandle{webRid: d.WebRid(), startedBy: "test", cancel: func() {}, done: make(chan struct{}), live: d}
+	roomsMu.Lock()
+	living[handle.webRid] = handle
+	roomsMu.Unlock()
+	testRoomTotal.WithLabelValues(metrics.Room("10000000002")).Inc()
+	if !hasRoomSeries(t, "10000000002") {
+		t.Fatal("处理消息后应有该直播间的指标序列")
+	}
+	removeRoom(handle)
+	if hasRoomSeries(t, "10000000002") {
+		t.Fatal("直播间退出后应删除其指标序列")
+	}
+
+	before := testutil.ToFloat64(reconnects.WithLabelValues(reconnectWatchlist))
+	roomsMu.Lock()
+	countReconnectLocked(&roomHandle{webRid: "10000000002", startedBy: watchlistPrincipal, launchedAt: time.Now()})
+	countReconnectLocked(&roomHandle{webRid: "10000000002", startedBy: watchlistPrincipal, launchedAt: time.Now()})
+	roomsMu.Unlock()
+	if got := testutil.ToFloat64(reconnects.WithLabelValues(reconnectWatchlist)) - before; got != 1 {
+		t.Fatalf("退出后再次开始应计一次重连，得到 %v", got)
+	}
+}
+
+// hasRoomSeries 默认 Registry 中是否有该直播间的序列
+func hasRoomSeries(t *testing.T, webRid string) bool {
+	t.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == metrics.RoomLabel && label.GetValue() == webRid {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
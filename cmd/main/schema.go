@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"douyinlive/database"
+	"douyinlive/model"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// 数据库不可用时重试创建表结构的间隔
+const (
+	migrateRetryMin = 5 * time.Second
+	migrateRetryMax = time.Minute
+)
+
+// schemaMigrated 表结构是否已创建或更新
+var schemaMigrated atomic.Bool
+
+// migrateSchema 创建或更新表结构，失败时在后台按退避间隔重试，直到成功或 ctx 结束
+func migrateSchema(ctx context.Context) {
+	if tryMigrate() {
+		return
+	}
+	go func() {
+		delay := migrateRetryMin
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			if tryMigrate() {
+				log.Println("数据库已恢复，表结构已创建或更新")
+				return
+			}
+			delay = min(delay*2, migrateRetryMax)
+		}
+	}()
+}
+
+// tryMigrate 执行一次建表，未启用数据库时视为成功
+func tryMigrate() bool {
+	if !database.Enabled() {
+		return true
+	}
+	if err := model.Migrate(); err != nil {
+		log.Printf("创建或更新表结构失败: %v\n", err)
+		return false
+	}
+	schemaMigrated.Store(true)
+	return true
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"douyinlive/config"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// serverConf 最终生效的服务配置
+var serverConf config.ServerConf
+
+// serverFlags 可覆盖服务配置的命令行参数
+type serverFlags struct {
+	port           string
+	addr           string
+	certFile       string
+	keyFile        string
+	allowedOrigins []string
+	readTimeout    int
+	writeTimeout   int
+	maxRooms       int
+}
+
+// register 注册命令行参数
+func (f *serverFlags) register(fs *pflag.FlagSet) {
+	fs.StringVar(&f.port, "port", "18080", "WebSocket 服务端口，等同于 --addr :端口")
+	fs.StringVar(&f.addr, "addr", "", "监听地址，如 0.0.0.0:18080")
+	fs.StringVar(&f.certFile, "tls-cert", "", "TLS 证书文件，与 --tls-key 同时设置时提供 https/wss")
+	fs.StringVar(&f.keyFile, "tls-key", "", "TLS 私钥文件")
+	fs.StringSliceVar(&f.allowedOrigins, "allowed-origins", nil, "允许的来源，多个以逗号分隔，* 表示全部")
+	fs.IntVar(&f.readTimeout, "read-timeout", 0, "读取请求的超时（秒）")
+	fs.IntVar(&f.writeTimeout, "write-timeout", 0, "写入响应的超时（秒）")
+	fs.IntVar(&f.maxRooms, "max-rooms", 0, "同时抓取的直播间上限，0 表示不限制")
+}
+
+// apply 按 命令行参数 > 环境变量 > 配置文件 的优先级生成服务配置
+func (f *serverFlags) apply(fs *pflag.FlagSet, conf config.ServerConf) config.ServerConf {
+	conf.LoadEnv()
+	if fs.Changed("port") {
+		conf.Addr = ":" + f.port
+	}
+	if fs.Changed("addr") {
+		conf.Addr = f.addr
+	}
+	if fs.Changed("tls-cert") {
+		conf.CertFile = f.certFile
+	}
+	if fs.Changed("tls-key") {
+		conf.KeyFile = f.keyFile
+	}
+	if fs.Changed("allowed-origins") {
+		conf.AllowedOrigins = f.allowedOrigins
+	}
+	if fs.Changed("read-timeout") {
+		conf.ReadTimeout = f.readTimeout
+	}
+	if fs.Changed("write-timeout") {
+		conf.WriteTimeout = f.writeTimeout
+	}
+	if fs.Changed("max-rooms") {
+		conf.MaxRooms = f.maxRooms
+	}
+	return conf
+}
+
+// originAllowed 来源是否允许，非浏览器请求没有 Origin 头，总是允许
+func originAllowed(origin string) bool {
+	if origin == "" || len(serverConf.AllowedOrigins) == 0 {
+		return true
+	}
+	for _, allowed := range serverConf.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkOrigin WebSocket 升级时校验来源
+func checkOrigin(r *http.Request) bool {
+	return originAllowed(r.Header.Get("Origin"))
+}
+
+// newServer 根据配置创建 HTTP 服务
+func newServer(conf config.ServerConf, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:         conf.Addr,
+		Handler:      handler,
+		ReadTimeout:  time.Duration(conf.ReadTimeout) * time.Second,
+		WriteTimeout: time.Duration(conf.WriteTimeout) * time.Second,
+	}
+}
+
+// listenAndServe 启动服务，配置了证书时提供 https/wss
+func listenAndServe(server *http.Server, conf config.ServerConf) error {
+	if conf.TLS() {
+		log.Printf("WebSocket 服务启动成功，地址为: wss://%s/ws/start\n", displayAddr(conf.Addr))
+		return server.ListenAndServeTLS(conf.CertFile, conf.KeyFile)
+	}
+	log.Printf("WebSocket 服务启动成功，地址为: ws://%s/ws/start\n", displayAddr(conf.Addr))
+	return server.ListenAndServe()
+}
+
+// displayAddr 日志中展示的地址，未指定主机时显示为 127.0.0.1
+func displayAddr(addr string) string {
+	if strings.HasPrefix(addr, ":") {
+		return "127.0.0.1" + addr
+	}
+	return addr
+}
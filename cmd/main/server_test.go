@@ -0,0 +1,55 @@
+package main
+
+import (
+	"douyinlive/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestServerFlagsPriority(t *testing.T) {
+	t.Setenv(config.EnvServerAddr, ":9000")
+	t.Setenv(config.EnvAllowedOrigins, "https://a.example.com, https://b.example.com")
+	t.Setenv(config.EnvReadTimeout, "15")
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	var flags serverFlags
+	flags.register(fs)
+	if err := fs.Parse([]string{"--port", "9100", "--tls-cert", "cert.pem"}); err != nil {
+		t.Fatal(err)
+	}
+	conf := flags.apply(fs, config.ServerConf{Addr: ":8000", KeyFile: "key.pem", WriteTimeout: 30})
+
+	if conf.Addr != ":9100" || !conf.TLS() || conf.ReadTimeout != 15 || conf.WriteTimeout != 30 {
+		t.Fatalf("配置优先级错误: %+v", conf)
+	}
+	if len(conf.AllowedOrigins) != 2 || conf.AllowedOrigins[1] != "https://b.example.com" {
+		t.Fatalf("来源解析错误: %+v", conf.AllowedOrigins)
+	}
+}
+
+func TestCorsAllowedOrigins(t *testing.T) {
+	serverConf = config.ServerConf{AllowedOrigins: []string{"https://a.example.com"}}
+	defer func() { serverConf = config.ServerConf{} }()
+	handler := corsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	r := httptest.NewRequest(http.MethodOptions, "/api/rooms", nil)
+	r.Header.Set("Origin", "https://a.example.com")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "https://a.example.com" {
+		t.Fatalf("允许的来源被拒绝: %d %v", w.Code, w.Header())
+	}
+
+	r.Header.Set("Origin", "https://evil.example.com")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusForbidden || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("未允许的来源应被拒绝: %d %v", w.Code, w.Header())
+	}
+	if checkOrigin(r) {
+		t.Fatal("WebSocket 升级应校验来源")
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"douyinlive/database"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// shutdownTimeout 关闭服务的总超时时间，超时后不再等待直播间与客户端退出
+	shutdownTimeout = 30 * time.Second
+	// shutdownReason 通知客户端的关闭原因
+	shutdownReason = "server_shutdown"
+)
+
+// shutdown 优雅关闭服务：停止接受新请求与直播间，等待直播间保存数据后退出，
+// 通知并断开 WebSocket 客户端，最后刷新未知消息存储，直播间全部退出时关闭数据库
+func shutdown(server *http.Server) {
+	log.Println("收到退出信号，开始关闭服务")
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// Shutdown 不会关闭已升级的 WebSocket 连接，客户端在直播间退出后单独关闭
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("关闭 HTTP 服务失败: %v\n", err)
+	}
+	// 先停止关注列表检查，避免关闭期间开始新的直播间
+	waitWatchlist(ctx)
+	roomsErr := stopAllRooms(ctx)
+	if roomsErr != nil {
+		log.Printf("等待直播间退出超时: %v\n", roomsErr)
+	} else {
+		log.Println("所有直播间已停止")
+	}
+	closeClients(ctx)
+
+	if unknownStore != nil {
+		if err := unknownStore.Close(); err != nil {
+			log.Printf("关闭未知消息存储失败: %v\n", err)
+		}
+	}
+	// 仍有直播间未退出时不关闭数据库，避免其保存数据时使用已关闭的连接，连接随进程退出释放
+	if roomsErr == nil {
+		if err := database.Close(); err != nil {
+			log.Printf("关闭数据库失败: %v\n", err)
+		}
+	} else {
+		log.Println("仍有直播间未退出，跳过关闭数据库")
+	}
+	log.Println("服务已关闭")
+}
+
+// closeClients 通知所有客户端服务即将关闭并断开连接，等待客户端断开直到 ctx 到期
+func closeClients(ctx context.Context) {
+	notification, _ := json.Marshal(map[string]interface{}{
+		"is_ok":   false,
+		"message": "服务正在关闭",
+		"data":    map[string]string{"reason": shutdownReason},
+	})
+	RangeConnections(func(agentID string, c *Client) {
+		_ = c.Send(notification)
+		c.CloseWithReason(websocket.CloseGoingAway, shutdownReason)
+	})
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for GetConnectionCount() > 0 {
+		select {
+		case <-ctx.Done():
+			log.Printf("仍有 %d 个客户端未断开\n", GetConnectionCount())
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"douyinlive"
+	"douyinlive/capture"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+)
+
+// unknownStore 未知消息语料库，仅在 --unknown 开启时创建
+var unknownStore *capture.Store
+
+// notificationMethods 服务内部使用的通知消息，不属于抖音协议
+var notificationMethods = map[string]bool{
+	"SuccessNotification": true,
+	"ErrNotification":     true,
+	"OffNotification":     true,
+}
+
+// captureUnknown 收集未注册或反序列化失败的消息
+func captureUnknown(eventData *douyinlive.Event) {
+	if unknownStore == nil || notificationMethods[eventData.Method] {
+		return
+	}
+	var reason string
+	switch {
+	case eventData.DecodeErr != nil:
+		reason = capture.ReasonDecodeFailed
+	case eventData.Decoded == nil:
+		reason = capture.ReasonUnregistered
+	default:
+		return
+	}
+	written, err := unknownStore.Capture(capture.Sample{
+		Method:  eventData.Method,
+		MsgId:   eventData.MsgId,
+		RoomId:  string(eventData.RoomID),
+		Reason:  reason,
+		Payload: eventData.Payload,
+	})
+	if err != nil {
+		log.Printf("保存未知消息失败: %v\n", err)
+		return
+	}
+	if written {
+		log.Printf("收集到未知消息样本: %s (%s)\n", eventData.Method, reason)
+	}
+}
+
+// runUnknowns 按出现次数列出语料库中的未知方法
+func runUnknowns(args []string) {
+	fs := pflag.NewFlagSet("unknowns", pflag.ExitOnError)
+	dir := fs.String("dir", "unknown", "未知消息存储目录")
+	_ = fs.Parse(args)
+
+	summaries, err := capture.Summarize(*dir)
+	if err != nil {
+		log.Fatalf("读取未知消息失败: %v", err)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tCOUNT\tSAMPLES\tSHAPES\tLAST SEEN")
+	for _, s := range summaries {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\n", s.Method, s.Count, s.Samples, s.Shapes, s.LastSeen.Format("2006-01-02 15:04:05"))
+	}
+	_ = w.Flush()
+}
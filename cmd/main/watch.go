@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"douyinlive"
+	"douyinlive/archive"
+	"douyinlive/richtext"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// watchEvent watch --json 输出的一行
+type watchEvent struct {
+	Time          int64           `json:"time"`
+	WebRid        string          `json:"web_rid"`
+	WebcastRoomId string          `json:"webcast_room_id"`
+	Method        string          `json:"method"`
+	MsgId         int64           `json:"msg_id,omitempty"`
+	Text          string          `json:"text"`
+	Data          json.RawMessage `json:"data,omitempty"`
+}
+
+// watchPrinter 按类型过滤并输出直播间消息
+type watchPrinter struct {
+	out     io.Writer
+	json    bool
+	include map[string]bool
+	exclude map[string]bool
+}
+
+// runWatch 连接单个直播间并将消息输出到标准输出，不需要配置文件与数据库
+func runWatch(args []string) {
+	fs := pflag.NewFlagSet("watch", pflag.ExitOnError)
+	jsonOut := fs.Bool("json", false, "以 NDJSON 格式输出")
+	methods := fs.StringSliceP("method", "m", nil, "只输出指定类型，可省略 Webcast 前缀，如 Chat,Gift")
+	exclude := fs.StringSliceP("exclude", "x", nil, "不输出的消息类型")
+	replay := fs.String("replay", "", "回放录制的帧归档，不连接直播间")
+	speed := fs.Float64("speed", 0, "回放倍速，0 表示不等待直接输出")
+	quiet := fs.BoolP("quiet", "q", false, "不输出运行日志")
+	_ = fs.Parse(args)
+
+	if *quiet {
+		log.SetOutput(io.Discard)
+	}
+	printer := &watchPrinter{
+		out:     os.Stdout,
+		json:    *jsonOut,
+		include: methodSet(*methods),
+		exclude: methodSet(*exclude),
+	}
+
+	var d *douyinlive.DouyinLive
+	var source douyinlive.FrameSource
+	var webRid douyinlive.WebRid
+	if *replay != "" {
+		r, err := archive.Open(*replay)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "打开归档失败: %v\n", err)
+			os.Exit(1)
+		}
+		d = douyinlive.NewReplayLive(r.Header)
+		source = douyinlive.NewReplaySource(r, douyinlive.ReplayOptions{Paced: *speed > 0, Speed: *speed})
+		webRid = d.WebRid()
+	} else {
+		if fs.NArg() != 1 {
+			fmt.Fprintln(os.Stderr, "用法: douyinlive watch [--json] [-m Chat,Gift] [-x Member] <web_rid|直播间链接|分享短链|主页链接>")
+			os.Exit(2)
+		}
+		var err error
+		webRid, err = douyinlive.ResolveWebRid(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "解析直播间失败: %v\n", err)
+			os.Exit(2)
+		}
+		d, err = douyinlive.NewDouyinLive(string(webRid))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "抖音链接失败: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	failed := false
+	d.Subscribe(func(message *douyinlive.Event) {
+		if message.Method == "ErrNotification" {
+			failed = true
+		}
+		printer.Print(message)
+	})
+
+	// Ctrl+C 时取消 context，关闭连接并退出读取循环
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if source != nil {
+		d.StartSource(ctx, source, 0)
+	} else {
+		d.Start(ctx, 0)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// methodSet 规范化消息类型列表，省略前缀时补全为 Webcast 开头的方法名
+func methodSet(methods []string) map[string]bool {
+	if len(methods) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		method = strings.TrimSpace(method)
+		if method == "" {
+			continue
+		}
+		if !strings.HasPrefix(method, "Webcast") && !notificationMethods[method] {
+			if !strings.HasSuffix(method, "Message") {
+				method += "Message"
+			}
+			method = "Webcast" + method
+		}
+		set[method] = true
+	}
+	return set
+}
+
+// Print 输出一条消息，被过滤的消息直接忽略
+func (p *watchPrinter) Print(message *douyinlive.Event) {
+	if p.exclude[message.Method] {
+		return
+	}
+	if p.include != nil && !p.include[message.Method] && !notificationMethods[message.Method] {
+		return
+	}
+	now := time.Now()
+	text := message.Method
+	if message.Decoded != nil {
+		text = richtext.Describe(message.Decoded)
+	}
+	if !p.json {
+		fmt.Fprintf(p.out, "%s %-28s %s\n", now.Format("15:04:05"), message.Method, text)
+		return
+	}
+	event := watchEvent{
+		Time:          now.UnixMilli(),
+		WebRid:        string(message.WebRid),
+		WebcastRoomId: string(message.RoomID),
+		Method:        message.Method,
+		MsgId:         message.MsgId,
+		Text:          text,
+	}
+	if message.Decoded != nil {
+		event.Data, _ = protojson.Marshal(message.Decoded)
+	}
+	line, _ := json.Marshal(event)
+	fmt.Fprintf(p.out, "%s\n", line)
+}
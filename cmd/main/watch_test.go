@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bytes"
+	"douyinlive/generated/douyin"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestWatchPrinterFilter(t *testing.T) {
+	var out bytes.Buffer
+	p := &watchPrinter{out: &out, json: true, include: methodSet([]string{"Chat", "WebcastGiftMessage"})}
+	chat, _ := proto.Marshal(&douyin.ChatMessage{User: &douyin.User{NickName: "观众"}, Content: "你好"})
+	p.Print(testEvent("1", &douyin.Message{Method: "WebcastChatMessage", Payload: chat}))
+	p.Print(testEvent("1", &douyin.Message{Method: "WebcastMemberMessage"}))
+	p.Print(testEvent("1", &douyin.Message{Method: "OffNotification"}))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("期望 2 行，得到 %q", out.String())
+	}
+	var event watchEvent
+	if err := json.Unmarshal([]byte(lines[0]), &event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Method != "WebcastChatMessage" || event.Data == nil || !strings.Contains(event.Text, "你好") {
+		t.Fatalf("输出错误: %+v", event)
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"douyinlive"
+	"douyinlive/config"
+	"douyinlive/resolver"
+	"douyinlive/watchlist"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultWatchlistFile     = "watchlist.json"
+	defaultWatchlistInterval = 60
+	// watchlistPrincipal 关注列表自动开始抓取时记录的调用方
+	watchlistPrincipal = "watchlist"
+)
+
+// 关注列表接口的错误码
+const (
+	codeWatchExists   = "watch_exists"
+	codeWatchNotFound = "watch_not_found"
+)
+
+// errUnrecognizedRoom 无法从请求中识别出 web_rid 或 sec_uid
+var errUnrecognizedRoom = errors.New("无法识别的直播间")
+
+var (
+	// watchStore 主播关注列表
+	watchStore *watchlist.Store
+	// watchDone 定时检查协程退出后关闭
+	watchDone chan struct{}
+)
+
+// watchRequest 添加关注的请求
+type watchRequest struct {
+	Room   string `json:"room"` // web_rid、直播间链接、分享短链或主页链接
+	Record bool   `json:"record"`
+}
+
+// watchItem 关注列表接口返回的条目
+type watchItem struct {
+	watchlist.Entry
+	Living bool `json:"living"`
+}
+
+// startWatchlist 加载关注列表并定时检查主播是否开播，ctx 取消后停止检查
+func startWatchlist(ctx context.Context, conf config.WatchConf) error {
+	if conf.File == "" {
+		conf.File = defaultWatchlistFile
+	}
+	if conf.Interval <= 0 {
+		conf.Interval = defaultWatchlistInterval
+	}
+	var err error
+	watchStore, err = watchlist.Open(conf.File)
+	if err != nil {
+		return err
+	}
+	watchDone = make(chan struct{})
+	go func() {
+		defer close(watchDone)
+		r := resolver.New()
+		ticker := time.NewTicker(time.Duration(conf.Interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			for _, entry := range watchStore.List() {
+				if ctx.Err() != nil {
+					return
+				}
+				checkAnchor(r, entry)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// checkAnchor 检查主播是否开播，开播且未在抓取时自动开始抓取
+func checkAnchor(r *resolver.Resolver, entry watchlist.Entry) {
+	if entry.WebRid != "" && isLiving(douyinlive.WebRid(entry.WebRid)) {
+		return
+	}
+	room, live, err := r.Live(resolver.Room{WebRid: entry.WebRid, SecUid: entry.SecUid})
+	if err != nil {
+		log.Printf("检查主播 %s 开播状态失败: %v\n", entry.Key(), err)
+		return
+	}
+	if !live {
+		return
+	}
+	if entry.WebRid == "" || entry.SecUid == "" {
+		key := entry.Key()
+		entry.WebRid, entry.SecUid = room.WebRid, room.SecUid
+		if err := watchStore.Update(key, entry); err != nil {
+			log.Printf("更新关注列表失败: %v\n", err)
+		}
+	}
+	webRid := douyinlive.WebRid(room.WebRid)
+	switch err := launchRoom(webRid, 0, entry.Record, watchlistPrincipal); {
+	case err == nil:
+		log.Printf("主播 %s 已开播，开始抓取直播间 %s\n", entry.Key(), webRid)
+	case !errors.Is(err, errRoomLiving):
+		log.Printf("开始抓取直播间 %s 失败: %v\n", webRid, err)
+	}
+}
+
+// handleWatchlist 查询、添加、删除关注的主播
+//
+//	GET    /api/watchlist
+//	POST   /api/watchlist            {"room": "...", "record": true}
+//	DELETE /api/watchlist?key=web_rid|sec_uid
+func handleWatchlist(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		entries := watchStore.List()
+		items := make([]watchItem, 0, len(entries))
+		for _, entry := range entries {
+			items = append(items, watchItem{
+				Entry:  entry,
+				Living: entry.WebRid != "" && isLiving(douyinlive.WebRid(entry.WebRid)),
+			})
+		}
+		writeJSON(w, http.StatusOK, items)
+	case http.MethodPost:
+		var req watchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, codeBadRequest, "请求解析失败: "+err.Error())
+			return
+		}
+		if req.Room == "" {
+			writeError(w, http.StatusBadRequest, codeBadRequest, "缺少 room")
+			return
+		}
+		entry, err := watchEntry(req)
+		switch {
+		case errors.Is(err, errUnrecognizedRoom):
+			writeError(w, http.StatusBadRequest, codeBadRequest, err.Error())
+			return
+		case err != nil:
+			writeError(w, http.StatusUnprocessableEntity, codeResolveFailed, err.Error())
+			return
+		}
+		switch err := watchStore.Add(entry); {
+		case errors.Is(err, watchlist.ErrMissingKey):
+			writeError(w, http.StatusBadRequest, codeBadRequest, err.Error())
+		case errors.Is(err, watchlist.ErrExists):
+			writeError(w, http.StatusConflict, codeWatchExists, err.Error())
+		case err != nil:
+			writeError(w, http.StatusInternalServerError, codeInternal, err.Error())
+		default:
+			writeJSON(w, http.StatusCreated, entry)
+		}
+	case http.MethodDelete:
+		key := r.URL.Query().Get("key")
+		removed, err := watchStore.Remove(key)
+		switch {
+		case errors.Is(err, watchlist.ErrMissingKey):
+			writeError(w, http.StatusBadRequest, codeBadRequest, err.Error())
+		case err != nil:
+			writeError(w, http.StatusInternalServerError, codeInternal, err.Error())
+		case !removed:
+			writeError(w, http.StatusNotFound, codeWatchNotFound, "主播不在关注列表中")
+		default:
+			writeJSON(w, http.StatusOK, map[string]string{"key": key, "result": "removed"})
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// waitWatchlist 等待定时检查协程退出，直到 ctx 到期
+func waitWatchlist(ctx context.Context) {
+	if watchDone == nil {
+		return
+	}
+	select {
+	case <-watchDone:
+	case <-ctx.Done():
+		log.Println("等待关注列表检查退出超时")
+	}
+}
+
+// watchEntry 将请求中的直播间解析为关注条目，能直接识别 web_rid 或 sec_uid 时不发起请求
+func watchEntry(req watchRequest) (watchlist.Entry, error) {
+	entry := watchlist.Entry{Record: req.Record}
+	room, ok := resolver.Parse(req.Room)
+	if !ok || (room.WebRid == "" && room.SecUid == "") {
+		var err error
+		if room, err = resolver.Resolve(req.Room); err != nil {
+			return entry, err
+		}
+	}
+	entry.WebRid, entry.SecUid = room.WebRid, room.SecUid
+	if entry.Key() == "" {
+		return entry, fmt.Errorf("%w: %s", errUnrecognizedRoom, req.Room)
+	}
+	return entry, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"douyinlive/config"
+	"douyinlive/watchlist"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchlistAPI(t *testing.T) {
+	// ctx 已取消，定时检查协程不会请求抖音
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := startWatchlist(ctx, config.WatchConf{File: filepath.Join(t.TempDir(), "watchlist.json"), Interval: 3600}); err != nil {
+		t.Fatal(err)
+	}
+	if err := watchStore.Add(watchlist.Entry{SecUid: "MS4wLjABAAAA"}); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	handleWatchlist(w, httptest.NewRequest(http.MethodDelete, "/api/watchlist", nil))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), codeBadRequest) {
+		t.Fatalf("缺少 key 应返回 400，得到 %d %s", w.Code, w.Body)
+	}
+	if len(watchStore.List()) != 1 {
+		t.Fatalf("不应删除只有 sec_uid 的主播: %+v", watchStore.List())
+	}
+
+	select {
+	case <-watchDone:
+	case <-time.After(time.Second):
+		t.Fatal("取消后关注列表检查未退出")
+	}
+
+	w = httptest.NewRecorder()
+	handleWatchlist(w, httptest.NewRequest(http.MethodDelete, "/api/watchlist?key=1", nil))
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), codeWatchNotFound) {
+		t.Fatalf("删除不存在的主播应返回 404，得到 %d %s", w.Code, w.Body)
+	}
+
+	// 纯数字的 web_rid 可直接识别，不会请求抖音
+	for _, tc := range []struct {
+		body string
+		want int
+	}{
+		{`{"room":"644826113301"}`, http.StatusCreated},
+		{`{"room":"644826113301"}`, http.StatusConflict},
+		{`{"room":""}`, http.StatusBadRequest},
+		{`{`, http.StatusBadRequest},
+	} {
+		w = httptest.NewRecorder()
+		handleWatchlist(w, httptest.NewRequest(http.MethodPost, "/api/watchlist", strings.NewReader(tc.body)))
+		if w.Code != tc.want {
+			t.Fatalf("POST %s 应返回 %d，得到 %d %s", tc.body, tc.want, w.Code, w.Body)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGeneratedUpToDate 提交的生成代码应与 methods.txt 一致，修改映射后需执行 go generate ./...
+func TestGeneratedUpToDate(t *testing.T) {
+	dir := t.TempDir()
+	mapOut, constOut := filepath.Join(dir, "messages_gen.go"), filepath.Join(dir, "methods_gen.go")
+	if err := generate("../../protobuf/douyin.proto", "../../protobuf/methods.txt", mapOut, constOut); err != nil {
+		t.Fatal(err)
+	}
+	for generated, committed := range map[string]string{
+		mapOut:   "../../generated/messages_gen.go",
+		constOut: "../../methods_gen.go",
+	} {
+		want, err := os.ReadFile(committed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(generated)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s 与 methods.txt 不一致，请执行 go generate ./...", committed)
+		}
+	}
+}
+
+func TestReadMappings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "methods.txt")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("# 注释\n\nWebcastChatMessage ChatMessage\nWebcastBindingGiftMessage NotifyEffectMessage.BindingGiftMessage\n")
+	mappings, err := readMappings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mappings) != 2 {
+		t.Fatalf("期望 2 条映射，得到 %d", len(mappings))
+	}
+	if m := mappings[1]; m.GoType != "NotifyEffectMessage_BindingGiftMessage" || m.Handler != "OnBindingGiftMessage" {
+		t.Fatalf("嵌套消息映射错误: %+v", m)
+	}
+
+	write("WebcastChatMessage ChatMessage\nWebcastChatMessage GiftMessage\n")
+	if _, err := readMappings(path); err == nil || !strings.Contains(err.Error(), "方法重复") {
+		t.Fatalf("期望方法重复错误，得到 %v", err)
+	}
+	write("WebcastChatMessage\n")
+	if _, err := readMappings(path); err == nil || !strings.Contains(err.Error(), "格式错误") {
+		t.Fatalf("期望格式错误，得到 %v", err)
+	}
+}
+
+func TestResolveMessages(t *testing.T) {
+	err := resolveMessages("../../protobuf/douyin.proto", []*mapping{{Method: "WebcastChatMessage", Message: "ChatMessage"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = resolveMessages("../../protobuf/douyin.proto", []*mapping{{Method: "WebcastFooMessage", Message: "FooMessage"}})
+	if err == nil || !strings.Contains(err.Error(), "FooMessage") {
+		t.Fatalf("期望消息不存在错误，得到 %v", err)
+	}
+}
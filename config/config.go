@@ -1,7 +1,11 @@
 package config
 
 import (
+	"errors"
 	"log"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -9,7 +13,61 @@ import (
 var Conf Config
 
 type Config struct {
-	DbConf MySQLConf `yaml:"dbConf"`
+	DbConf      MySQLConf   `yaml:"dbConf"`
+	ProtoConf   ProtoConf   `yaml:"protoConf"`
+	WatchConf   WatchConf   `yaml:"watchConf"`
+	ForwardConf ForwardConf `yaml:"forwardConf"`
+	ServerConf  ServerConf  `yaml:"serverConf"`
+	AuthConf    AuthConf    `yaml:"authConf"`
+	MetricsConf MetricsConf `yaml:"metricsConf"`
+	StorageConf StorageConf `yaml:"storageConf"`
+}
+
+// 存储驱动
+const (
+	StorageNone   = "none"   // 不入库
+	StorageSQLite = "sqlite" // 本地 SQLite 文件，无需额外服务
+	StorageMySQL  = "mysql"  // 使用 DbConf 连接 MySQL
+)
+
+// DefaultSQLitePath 默认的 SQLite 数据库文件
+const DefaultSQLitePath = "douyinlive.db"
+
+// StorageConf 持久化配置
+type StorageConf struct {
+	Driver string // none、sqlite 或 mysql，为空时配置了 DbConf 则使用 mysql，否则不入库
+	Path   string // SQLite 数据库文件，默认 douyinlive.db
+}
+
+// 可覆盖 StorageConf 的环境变量
+const (
+	EnvStorageDriver = "DOUYINLIVE_STORAGE"
+	EnvSQLitePath    = "DOUYINLIVE_SQLITE_PATH"
+)
+
+// LoadEnv 使用环境变量覆盖存储配置
+func (c *StorageConf) LoadEnv() {
+	if v := os.Getenv(EnvStorageDriver); v != "" {
+		c.Driver = v
+	}
+	if v := os.Getenv(EnvSQLitePath); v != "" {
+		c.Path = v
+	}
+}
+
+// Resolve 补全默认值：未指定驱动时根据是否配置了 MySQL 决定
+func (c StorageConf) Resolve(mysql MySQLConf) StorageConf {
+	c.Driver = strings.ToLower(strings.TrimSpace(c.Driver))
+	if c.Driver == "" {
+		c.Driver = StorageNone
+		if mysql != (MySQLConf{}) {
+			c.Driver = StorageMySQL
+		}
+	}
+	if c.Driver == StorageSQLite && c.Path == "" {
+		c.Path = DefaultSQLitePath
+	}
+	return c
 }
 
 type MySQLConf struct {
@@ -22,6 +80,129 @@ type MySQLConf struct {
 	MaxLifetime  int
 }
 
+// ProtoConf 运行时加载的 .proto 文件，无需重新编译即可解析新的消息类型
+type ProtoConf struct {
+	Files       []string        // 要加载的 .proto 文件
+	ImportPaths []string        // import 搜索路径，内置的 douyin.proto 可直接 import
+	Methods     []MethodMapping // 方法名与消息名的映射
+}
+
+// MethodMapping 将 Message.method 映射到 .proto 中的消息全名
+type MethodMapping struct {
+	Method  string
+	Message string
+}
+
+// WatchConf 主播关注列表，主播开播后自动开始抓取
+type WatchConf struct {
+	File     string // 关注列表文件，默认 watchlist.json
+	Interval int    // 检查开播状态的间隔（秒），默认 60
+}
+
+// ForwardConf 向 WebSocket 客户端转发消息时的 protojson 选项
+type ForwardConf struct {
+	UseProtoNames   bool // 使用 proto 中的字段名（如 nick_name），默认使用 lowerCamelCase
+	EmitUnpopulated bool // 输出零值字段
+	UseEnumNumbers  bool // 枚举输出为数字
+}
+
+// ServerConf HTTP/WebSocket 服务配置，可被环境变量与命令行参数覆盖
+type ServerConf struct {
+	Addr           string   // 监听地址，默认 :18080
+	CertFile       string   // TLS 证书，与 KeyFile 同时设置时提供 https/wss
+	KeyFile        string   // TLS 私钥
+	AllowedOrigins []string // 允许的来源，同时用于 CORS 与 WebSocket 升级，为空或包含 * 时允许全部
+	ReadTimeout    int      // 读取请求的超时（秒），0 表示不限制
+	WriteTimeout   int      // 写入响应的超时（秒），0 表示不限制，WebSocket 连接升级后不受影响
+	MaxRooms       int      // 同时抓取的直播间上限，0 表示不限制，达到上限时 /readyz 报告未就绪
+}
+
+// MetricsConf /metrics 指标配置
+type MetricsConf struct {
+	PerRoom bool // 是否按直播间区分指标，直播间较多时关闭以控制序列数量
+}
+
+// AuthConf 接口鉴权配置，Keys 与 JWTSecret 都为空时不启用鉴权
+type AuthConf struct {
+	Keys      []APIKey // 静态 API Key
+	JWTSecret string   // HS256 签名密钥，设置后接受签名的 JWT
+}
+
+// APIKey 一个静态 API Key 及其权限
+type APIKey struct {
+	Name   string   // 调用方名称，记录在其开始抓取的直播间上
+	Key    string   // 通过 Authorization: Bearer、X-API-Key 头或 token 参数传递
+	Scopes []string // read、start、stop，* 表示全部
+}
+
+// EnvJWTSecret 可覆盖 AuthConf.JWTSecret 的环境变量，避免将密钥写入配置文件
+const EnvJWTSecret = "DOUYINLIVE_JWT_SECRET"
+
+// LoadEnv 使用环境变量覆盖 JWT 密钥
+func (c *AuthConf) LoadEnv() {
+	if v := os.Getenv(EnvJWTSecret); v != "" {
+		c.JWTSecret = v
+	}
+}
+
+// 可覆盖 ServerConf 的环境变量
+const (
+	EnvServerAddr     = "DOUYINLIVE_ADDR"
+	EnvServerCertFile = "DOUYINLIVE_TLS_CERT"
+	EnvServerKeyFile  = "DOUYINLIVE_TLS_KEY"
+	EnvAllowedOrigins = "DOUYINLIVE_ALLOWED_ORIGINS" // 多个来源以逗号分隔
+	EnvReadTimeout    = "DOUYINLIVE_READ_TIMEOUT"
+	EnvWriteTimeout   = "DOUYINLIVE_WRITE_TIMEOUT"
+	EnvMaxRooms       = "DOUYINLIVE_MAX_ROOMS"
+)
+
+// DefaultServerAddr 默认监听地址
+const DefaultServerAddr = ":18080"
+
+// LoadEnv 使用环境变量覆盖配置，未设置的环境变量不影响原值
+func (c *ServerConf) LoadEnv() {
+	if v := os.Getenv(EnvServerAddr); v != "" {
+		c.Addr = v
+	}
+	if v := os.Getenv(EnvServerCertFile); v != "" {
+		c.CertFile = v
+	}
+	if v := os.Getenv(EnvServerKeyFile); v != "" {
+		c.KeyFile = v
+	}
+	if v := os.Getenv(EnvAllowedOrigins); v != "" {
+		c.AllowedOrigins = SplitList(v)
+	}
+	if v, err := strconv.Atoi(os.Getenv(EnvReadTimeout)); err == nil {
+		c.ReadTimeout = v
+	}
+	if v, err := strconv.Atoi(os.Getenv(EnvWriteTimeout)); err == nil {
+		c.WriteTimeout = v
+	}
+	if v, err := strconv.Atoi(os.Getenv(EnvMaxRooms)); err == nil {
+		c.MaxRooms = v
+	}
+	if c.Addr == "" {
+		c.Addr = DefaultServerAddr
+	}
+}
+
+// TLS 是否启用 TLS
+func (c *ServerConf) TLS() bool {
+	return c.CertFile != "" && c.KeyFile != ""
+}
+
+// SplitList 按逗号拆分并去除空白
+func SplitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 func Init() {
 	v := viper.New()
 	v.SetConfigName("config")
@@ -29,6 +210,11 @@ func Init() {
 	v.AddConfigPath(".")
 
 	err := v.ReadInConfig()
+	if errors.As(err, &viper.ConfigFileNotFoundError{}) {
+		// 没有配置文件时使用默认配置，可通过环境变量与命令行参数调整
+		log.Println("未找到 config.yaml，使用默认配置")
+		return
+	}
 	if err != nil {
 		log.Fatalln("Fatal error config file  err: ", err)
 	}
@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"douyinlive/config"
+	"errors"
+	"fmt"
+)
+
+var (
+	// initErr 最近一次连接数据库的错误
+	initErr error
+	// driver 当前使用的存储驱动
+	driver string
+)
+
+// Init 按存储配置连接数据库，conf 需先经过 StorageConf.Resolve 补全
+func Init(conf config.StorageConf, mysqlConf config.MySQLConf) error {
+	driver = conf.Driver
+	switch conf.Driver {
+	case config.StorageNone:
+		return nil
+	case config.StorageSQLite:
+		return InitSQLite(conf.Path)
+	case config.StorageMySQL:
+		return InitRMSDB(mysqlConf)
+	default:
+		initErr = fmt.Errorf("未知的存储驱动: %s", conf.Driver)
+		return initErr
+	}
+}
+
+// Driver 当前使用的存储驱动，未调用 Init 时为空
+func Driver() string {
+	return driver
+}
+
+// Enabled 是否已初始化数据库，watch 等命令不连接数据库时为 false，持久化操作将被跳过
+func Enabled() bool {
+	return DB != nil
+}
+
+// Close 关闭数据库连接池，未初始化时不做处理
+func Close() error {
+	if DB == nil {
+		return nil
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	DB = nil
+	return sqlDB.Close()
+}
+
+// Ping 检查数据库连接，未能创建连接的返回当时的错误，配置为不入库时总是成功
+func Ping(ctx context.Context) error {
+	if DB == nil && driver == config.StorageNone {
+		return nil
+	}
+	if DB == nil {
+		if initErr != nil {
+			return initErr
+		}
+		return errors.New("数据库未初始化")
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
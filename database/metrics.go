@@ -0,0 +1,42 @@
+package database
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+var (
+	insertDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "douyinlive_db_insert_duration_seconds",
+		Help: "数据库插入耗时",
+	}, []string{"table"})
+	insertErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "douyinlive_db_insert_errors_total",
+		Help: "数据库插入失败次数",
+	}, []string{"table"})
+)
+
+const insertStartKey = "metrics:insert_start"
+
+// registerMetrics 通过 gorm 回调统计插入耗时与失败次数，table 标签取值为固定的表名
+func registerMetrics(db *gorm.DB) error {
+	create := db.Callback().Create()
+	err := create.Before("gorm:create").Register("metrics:before_create", func(tx *gorm.DB) {
+		tx.InstanceSet(insertStartKey, time.Now())
+	})
+	if err != nil {
+		return err
+	}
+	return create.After("gorm:create").Register("metrics:after_create", func(tx *gorm.DB) {
+		table := tx.Statement.Table
+		if start, ok := tx.InstanceGet(insertStartKey); ok {
+			insertDuration.WithLabelValues(table).Observe(time.Since(start.(time.Time)).Seconds())
+		}
+		if tx.Error != nil {
+			insertErrors.WithLabelValues(table).Inc()
+		}
+	})
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"douyinlive/config"
+	"errors"
 	"fmt"
 	"time"
 
@@ -13,13 +14,25 @@ import (
 var dbMap map[string]*gorm.DB
 var DB *gorm.DB
 
-func InitRMSDB(conf config.MySQLConf) {
-	DB = initDB(conf)
+// InitRMSDB 创建 MySQL 连接池，启动时不要求 MySQL 可用：配置有误时返回错误且 DB 保持为 nil；
+// MySQL 暂时不可用时仍设置 DB 并返回 ping 的错误，连接池在 MySQL 恢复后自动重连
+func InitRMSDB(conf config.MySQLConf) error {
+	db, err := initDB(conf)
+	initErr = err
+	if err != nil {
+		return err
+	}
+	DB = db
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Ping()
 }
 
-func initDB(conf config.MySQLConf) *gorm.DB {
+func initDB(conf config.MySQLConf) (*gorm.DB, error) {
 	if conf == (config.MySQLConf{}) {
-		panic("init gorm failed, please confirm the MySQL config is set correctly")
+		return nil, errors.New("init gorm failed, please confirm the MySQL config is set correctly")
 	}
 
 	//@parseTime MySQL中的DATE、DATETIME、TIMESTAMP等时间类型字段将自动转换为golang中的time.Time类型。
@@ -27,18 +40,23 @@ func initDB(conf config.MySQLConf) *gorm.DB {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8&parseTime=True&loc=UTC",
 		conf.Username, conf.Password, conf.Host, conf.Database)
 
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger:      logger.Default.LogMode(logger.Info),
-		PrepareStmt: true,
-		QueryFields: true,
+	// 跳过查询版本与自动 ping，MySQL 未启动时也能创建连接池
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: dsn, SkipInitializeWithVersion: true}), &gorm.Config{
+		Logger:               logger.Default.LogMode(logger.Info),
+		PrepareStmt:          true,
+		QueryFields:          true,
+		DisableAutomaticPing: true,
 	})
 	if err != nil {
-		panic(err)
+		return nil, err
+	}
+	if err := registerMetrics(db); err != nil {
+		return nil, err
 	}
 
 	sqlDB, err := db.DB()
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	// SetMaxIdleConns 设置空闲连接池中连接的最大数量
@@ -51,7 +69,7 @@ func initDB(conf config.MySQLConf) *gorm.DB {
 	sqlDB.SetConnMaxLifetime(time.Duration(conf.MaxLifetime) * time.Second)
 
 	addDBMap(conf.Database, db)
-	return db
+	return db, nil
 }
 
 func addDBMap(database string, db *gorm.DB) {
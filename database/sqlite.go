@@ -0,0 +1,42 @@
+package database
+
+import (
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// InitSQLite 打开 SQLite 数据库文件，文件不存在时自动创建
+//
+// 使用纯 Go 实现的驱动，不依赖 cgo；表结构与 MySQL 相同，由 model.Migrate 创建
+func InitSQLite(path string) error {
+	db, err := initSQLite(path)
+	initErr = err
+	if err != nil {
+		return err
+	}
+	DB = db
+	return nil
+}
+
+func initSQLite(path string) (*gorm.DB, error) {
+	// WAL 允许读写并发，busy_timeout 避免多个直播间同时写入时立即返回 SQLITE_BUSY
+	dsn := path + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Warn),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := registerMetrics(db); err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	// SQLite 同一时间只允许一个写入者
+	sqlDB.SetMaxOpenConns(1)
+	return db, nil
+}
@@ -3,16 +3,20 @@ package douyinlive
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
+	"douyinlive/archive"
+	"douyinlive/generated"
 	"douyinlive/generated/douyin"
 	"douyinlive/jsScript"
 	"douyinlive/model"
+	"douyinlive/resolver"
 	"douyinlive/utils"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"regexp"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -29,41 +33,27 @@ var (
 	pushIDRegexp = regexp.MustCompile(`user_unique_id\\":\\"(\d+)\\"`)
 )
 
-type StopChanData struct {
-	RoomId int
-}
-
-var StopChan = make(chan StopChanData)
-var LivingRoomIds []int
+// LiveStatusEnded ControlMessage 中表示直播结束的状态
+const LiveStatusEnded = 3
 
 // DouyinLive 结构体表示一个抖音直播连接
 
-// NewDouyinLive 创建一个新的 DouyinLive 实例
-func NewDouyinLive(liveid string) (*DouyinLive, error) {
-	ua := utils.RandomUserAgent()
-	c := req.C().SetUserAgent(ua)
-	d := &DouyinLive{
-		liveid:        liveid,
-		liveurl:       "https://live.douyin.com/",
-		userAgent:     ua,
-		c:             c,
-		eventHandlers: make([]EventHandler, 0),
-		headers:       http.Header{},
-		buffers: &sync.Pool{
-			New: func() interface{} {
-				return &bytes.Buffer{}
-			}},
+// NewDouyinLive 创建一个新的 DouyinLive 实例，room 可以是 web_rid、直播间链接、分享短链或主播主页链接
+func NewDouyinLive(room string) (*DouyinLive, error) {
+	webRid, err := ResolveWebRid(room)
+	if err != nil {
+		return nil, fmt.Errorf("解析直播间失败: %w", err)
 	}
+	d := newDouyinLive(webRid, utils.RandomUserAgent())
 
 	// 获取 ttwid
-	var err error
 	d.ttwid, err = d.fetchTTWID()
 	if err != nil {
 		return nil, fmt.Errorf("获取 TTWID 失败: %w", err)
 	}
 
-	// 获取 roomid
-	d.roomid = d.fetchRoomID()
+	// 获取 roomID
+	d.roomID = d.fetchRoomID()
 
 	// 加载 JavaScript 脚本
 	err = jsScript.LoadGoja(d.userAgent)
@@ -73,6 +63,46 @@ func NewDouyinLive(liveid string) (*DouyinLive, error) {
 	return d, nil
 }
 
+// ResolveWebRid 将任意形式的直播间标识解析为 web_rid，输入本身是 web_rid 或直播间链接时不发起请求
+func ResolveWebRid(input string) (WebRid, error) {
+	if room, ok := resolver.Parse(input); ok && room.WebRid != "" {
+		return WebRid(room.WebRid), nil
+	}
+	room, err := resolver.Resolve(input)
+	if err != nil {
+		return "", err
+	}
+	return WebRid(room.WebRid), nil
+}
+
+// NewReplayLive 根据归档头部创建不联网的 DouyinLive，配合 StartSource 回放录制的帧
+func NewReplayLive(header archive.Header) *DouyinLive {
+	d := newDouyinLive(WebRid(header.WebRid), header.Device.UserAgent)
+	d.roomID = RoomID(header.RoomId)
+	d.pushid = header.Device.DeviceId
+	return d
+}
+
+// newDouyinLive 初始化 DouyinLive 的内部状态，不发起网络请求
+func newDouyinLive(webRid WebRid, ua string) *DouyinLive {
+	c := req.C().SetUserAgent(ua)
+	return &DouyinLive{
+		webRid:        webRid,
+		liveurl:       "https://live.douyin.com/",
+		userAgent:     ua,
+		c:             c,
+		eventHandlers: make([]EventHandler, 0),
+		headers:       http.Header{},
+		viewers:       NewViewerTracker(nil),
+		social:        NewSocialTracker(),
+		shopping:      NewShoppingTracker(),
+		buffers: &sync.Pool{
+			New: func() interface{} {
+				return &bytes.Buffer{}
+			}},
+	}
+}
+
 // fetchTTWID 获取 ttwid
 func (d *DouyinLive) fetchTTWID() (string, error) {
 	if d.ttwid != "" {
@@ -94,9 +124,9 @@ func (d *DouyinLive) fetchTTWID() (string, error) {
 }
 
 // fetchRoomID 获取 roomID
-func (d *DouyinLive) fetchRoomID() string {
-	if d.roomid != "" {
-		return d.roomid
+func (d *DouyinLive) fetchRoomID() RoomID {
+	if d.roomID != "" {
+		return d.roomID
 	}
 
 	t, _ := d.fetchTTWID()
@@ -108,15 +138,15 @@ func (d *DouyinLive) fetchRoomID() string {
 		Name:  "__ac_nonce",
 		Value: "0123407cc00a9e438deb4",
 	}
-	res, err := d.c.R().SetCookies(ttwid, acNonce).Get(d.liveurl + d.liveid)
+	res, err := d.c.R().SetCookies(ttwid, acNonce).Get(d.liveurl + string(d.webRid))
 	if err != nil {
 		log.Printf("获取房间 ID 失败: %v", err)
 		return ""
 	}
 
-	d.roomid = extractMatch(roomIDRegexp, res.String())
+	d.roomID = RoomID(extractMatch(roomIDRegexp, res.String()))
 	d.pushid = extractMatch(pushIDRegexp, res.String())
-	return d.roomid
+	return d.roomID
 }
 
 // extractMatch 从字符串中提取正则表达式匹配的内容
@@ -166,25 +196,57 @@ func (d *DouyinLive) GzipUnzipReset(compressedData []byte) ([]byte, error) {
 	return uncompressedBuffer.Bytes(), nil
 }
 
-// Start 开始连接和处理消息
-func (d *DouyinLive) Start(roomId, liveId int) {
+// Start 开始连接和处理消息，session 为本服务分配的场次 ID，ctx 取消后断开连接并返回
+func (d *DouyinLive) Start(ctx context.Context, session SessionID) {
 	var err error
+	d.setSession(session)
 	d.wssurl = d.StitchUrl()
 	d.headers.Add("user-agent", d.userAgent)
 	d.headers.Add("cookie", fmt.Sprintf("ttwid=%s", d.ttwid))
 	var response *http.Response
-	d.Conn, response, err = websocket.DefaultDialer.Dial(d.wssurl, d.headers)
+	d.Conn, response, err = websocket.DefaultDialer.DialContext(ctx, d.wssurl, d.headers)
 	if err != nil {
-		LivingRoomIds = utils.RemoveElement(LivingRoomIds, roomId)
-		log.Printf("链接失败: err:%v\nroomid:%v\nresponse:%v\n", err, roomId, response)
-		d.emit(&douyin.Message{RoomId: roomId, Method: "ErrNotification"})
+		log.Printf("链接失败: err:%v\nweb_rid:%v\nresponse:%v\n", err, d.webRid, response)
+		d.notify("ErrNotification")
 		return
 	}
+	d.run(ctx, &wsSource{conn: d.Conn})
+}
+
+// StartSource 使用指定的帧来源处理消息，例如 ReplaySource 回放录制的帧
+func (d *DouyinLive) StartSource(ctx context.Context, source FrameSource, session SessionID) {
+	d.setSession(session)
+	d.run(ctx, source)
+}
+
+// run 从帧来源读取 PushFrame，解压、解析并处理消息，直到来源结束或 ctx 取消
+func (d *DouyinLive) run(ctx context.Context, source FrameSource) {
 	d.isLiveClosed = true
-	d.emit(&douyin.Message{RoomId: roomId, Method: "SuccessNotification"})
-	log.Printf("直播间%s链接成功\n", strconv.Itoa(roomId))
+	d.startedAt.Store(time.Now().UnixNano())
+	audience, err := LoadAnchorAudience(string(d.webRid))
+	if err != nil {
+		log.Printf("加载主播历史观众失败: %v\n", err)
+	}
+	d.stateMu.Lock()
+	d.viewers = NewViewerTracker(audience)
+	d.social = NewSocialTracker()
+	d.shopping = NewShoppingTracker()
+	d.stateMu.Unlock()
+	d.notify("SuccessNotification")
+	log.Printf("直播间%s链接成功\n", d.webRid)
+
+	// ctx 取消时关闭来源，使阻塞中的 ReadFrame 立即返回
+	var closeOnce sync.Once
+	var closeErr error
+	closeSource := func() {
+		closeOnce.Do(func() {
+			closeErr = source.Close()
+		})
+	}
+	stopAfter := context.AfterFunc(ctx, closeSource)
 
 	defer func() {
+		stopAfter()
 		if d.gzip != nil {
 			err := d.gzip.Close()
 			if err != nil {
@@ -193,35 +255,36 @@ func (d *DouyinLive) Start(roomId, liveId int) {
 				log.Println("gzip关闭")
 			}
 		}
-		if d.Conn != nil {
-			err = d.Conn.Close()
-			if err != nil {
-				log.Println("关闭ws链接失败", err)
-			} else {
-				log.Println("抖音ws链接关闭")
-			}
+		closeSource()
+		if closeErr != nil {
+			log.Println("关闭ws链接失败", closeErr)
+		} else {
+			log.Println("抖音ws链接关闭")
 		}
 
-		LivingRoomIds = utils.RemoveElement(LivingRoomIds, roomId)
-		log.Printf("直播间%s链接已关闭\n", strconv.Itoa(roomId))
-		d.emit(&douyin.Message{RoomId: roomId, Method: "OffNotification"})
+		if err := d.StopRecording(); err != nil {
+			log.Println("关闭录制文件失败", err)
+		}
+		d.saveAudience()
+		d.saveSocial()
+		d.saveShopping()
+		log.Printf("直播间%s链接已关闭\n", d.webRid)
+		d.notify("OffNotification")
 	}()
 	var pbPac = &douyin.PushFrame{}
 	var pbResp = &douyin.Response{}
 	var pbAck = &douyin.PushFrame{}
 	for d.isLiveClosed {
 		select {
-		//判断chan信息是否是当前的直播间id
-		case stopChanData := <-StopChan:
-			if stopChanData.RoomId == roomId {
-				d.isLiveClosed = false
-				fmt.Println("关闭通道")
-				break
-			}
+		case <-ctx.Done():
+			d.isLiveClosed = false
+			log.Printf("直播间%s收到停止信号: %v\n", d.webRid, context.Cause(ctx))
 		default:
-			messageType, message, err := d.Conn.ReadMessage()
+			message, err := source.ReadFrame()
 			if err != nil {
-				log.Println("读取消息失败-", err, message, messageType)
+				if ctx.Err() == nil {
+					log.Println("读取消息失败-", err)
+				}
 				d.isLiveClosed = false
 				fmt.Println("关闭通道")
 				break
@@ -233,8 +296,11 @@ func (d *DouyinLive) Start(roomId, liveId int) {
 				//}
 			} else {
 				if message != nil {
+					d.frames.Add(1)
+					d.recordFrame(message)
 					err := proto.Unmarshal(message, pbPac)
 					if err != nil {
+						decodeFailures.WithLabelValues(stageFrame, d.roomLabel()).Inc()
 						log.Println("解析消息失败：", err)
 						continue
 					}
@@ -242,12 +308,14 @@ func (d *DouyinLive) Start(roomId, liveId int) {
 					if n && pbPac.PayloadType == "msg" {
 						uncompressedData, err := d.GzipUnzipReset(pbPac.Payload)
 						if err != nil {
+							decodeFailures.WithLabelValues(stageGzip, d.roomLabel()).Inc()
 							log.Println("Gzip解压失败:", err)
 							continue
 						}
 
 						err = proto.Unmarshal(uncompressedData, pbResp)
 						if err != nil {
+							decodeFailures.WithLabelValues(stageResponse, d.roomLabel()).Inc()
 							log.Println("解析消息失败：", err)
 							continue
 						}
@@ -262,13 +330,14 @@ func (d *DouyinLive) Start(roomId, liveId int) {
 								log.Println("proto心跳包序列化失败:", err)
 								continue
 							}
-							err = d.Conn.WriteMessage(websocket.BinaryMessage, serializedAck)
+							err = source.WriteFrame(serializedAck)
 							if err != nil {
 								log.Println("心跳包发送失败：", err)
 								continue
 							}
+							acksSent.WithLabelValues(d.roomLabel()).Inc()
 						}
-						d.ProcessingMessage(pbResp, liveId)
+						d.ProcessingMessage(pbResp)
 					}
 				}
 			}
@@ -305,9 +374,12 @@ func (d *DouyinLive) reconnect(i int) bool {
 
 // StitchUrl 构建 WebSocket 连接的 URL
 func (d *DouyinLive) StitchUrl() string {
-	smap := utils.NewOrderedMap(d.roomid, d.pushid)
+	roomID := string(d.roomID)
+	smap := utils.NewOrderedMap(roomID, d.pushid)
 	signaturemd5 := utils.GetxMSStub(smap)
+	signStart := time.Now()
 	signature := jsScript.ExecuteJS(signaturemd5)
+	signDuration.Observe(time.Since(signStart).Seconds())
 	browserInfo := strings.Split(d.userAgent, "Mozilla")[1]
 	parsedURL := strings.Replace(browserInfo[1:], " ", "%20", -1)
 	fetchTime := time.Now().UnixNano() / int64(time.Millisecond)
@@ -316,70 +388,283 @@ func (d *DouyinLive) StitchUrl() string {
 		"=web&cookie_enabled=true&screen_width=1920&screen_height=1080&browser_language=zh-CN&browser_platform=Win32&" +
 		"browser_name=Mozilla&browser_version=" + parsedURL + "&browser_online=true" +
 		"&tz_name=Asia/Shanghai&cursor=d-1_u-1_fh-7383731312643626035_t-1719159695790_r-1&internal_ext" +
-		"=internal_src:dim|wss_push_room_id:" + d.roomid + "|wss_push_did:" + d.pushid + "|first_req_ms:" + cast.ToString(fetchTime) + "|fetch_time:" + cast.ToString(fetchTime) + "|seq:1|wss_info:0-" + cast.ToString(fetchTime) + "-0-0|" +
+		"=internal_src:dim|wss_push_room_id:" + roomID + "|wss_push_did:" + d.pushid + "|first_req_ms:" + cast.ToString(fetchTime) + "|fetch_time:" + cast.ToString(fetchTime) + "|seq:1|wss_info:0-" + cast.ToString(fetchTime) + "-0-0|" +
 		"wrds_v:7382620942951772256&host=https://live.douyin.com&aid=6383&live_id=1&did_rule=3" +
 		"&endpoint=live_pc&support_wrds=1&user_unique_id=" + d.pushid + "&im_path=/webcast/im/fetch/" +
-		"&identity=audience&need_persist_msg_count=15&insert_task_id=&live_reason=&room_id=" + d.roomid + "&heartbeatDuration=0&signature=" + signature
+		"&identity=audience&need_persist_msg_count=15&insert_task_id=&live_reason=&room_id=" + roomID + "&heartbeatDuration=0&signature=" + signature
 }
 
 // emit 触发事件处理器
-func (d *DouyinLive) emit(eventData *douyin.Message) {
+func (d *DouyinLive) emit(event *Event) {
 	for _, handler := range d.eventHandlers {
-		handler(eventData)
+		handler(event)
+	}
+}
+
+// notify 发送服务内部的通知消息
+func (d *DouyinLive) notify(method string) {
+	d.emit(d.event(&douyin.Message{Method: method}))
+}
+
+// event 为消息附上所属的直播间与场次
+func (d *DouyinLive) event(message *douyin.Message) *Event {
+	return &Event{
+		Message: message,
+		WebRid:  d.webRid,
+		RoomID:  d.roomID,
+		Session: d.session,
 	}
 }
 
 // ProcessingMessage 处理接收到的消息
-func (d *DouyinLive) ProcessingMessage(response *douyin.Response, liveId int) {
+func (d *DouyinLive) ProcessingMessage(response *douyin.Response) {
+	handlers := d.messageHandlers()
+	room := d.roomLabel()
 	for _, data := range response.MessagesList {
-		//if data.Method == "WebcastControlMessage" {
-		//	msg := &douyin.ControlMessage{}
-		//	err := proto.Unmarshal(data.Payload, msg)
-		//	if err != nil {
-		//		log.Println("解析protobuf失败", err)
-		//		return
-		//	}
-		//	if msg.Status == 3 {
-		//		d.isLiveClosed = false
-		//		log.Println("关闭ws链接成功")
-		//	}
-		//}
-
-		if data.Method == "WebcastChatMessage" {
-			d.emit(data)
-			msg := &douyin.ChatMessage{}
-			err := proto.Unmarshal(data.Payload, msg)
-			if err != nil {
-				log.Println("解析protobuf失败", err)
-				return
-			}
-			log.Println("聊天msg", msg.User.NickName, msg.Content)
+		d.messages.Add(1)
+		messagesReceived.WithLabelValues(methodLabel(data.Method), room).Inc()
+		event := d.event(data)
+		if err := decodeEvent(event); err != nil {
+			decodeFailures.WithLabelValues(stageMessage, room).Inc()
+			log.Println("解析protobuf失败", data.Method, err)
+		}
+		d.emit(event)
+
+		if event.Decoded != nil {
+			handlers.Handle(data.Method, event.Decoded)
+		}
+	}
+}
+
+// decodeEvent 解码已注册的消息，结果供订阅者与内部处理函数共用，每条消息只解码一次
+func decodeEvent(event *Event) error {
+	newMessage, ok := generated.MessageMap[event.Method]
+	if !ok {
+		return nil
+	}
+	msg := newMessage()
+	if err := proto.Unmarshal(event.Payload, msg); err != nil {
+		event.DecodeErr = err
+		return err
+	}
+	event.Decoded = msg
+	return nil
+}
+
+// messageHandlers 内部统计与入库使用的强类型处理函数
+func (d *DouyinLive) messageHandlers() *generated.Handlers {
+	return &generated.Handlers{
+		OnChatMessage: func(msg *douyin.ChatMessage) {
+			log.Println("聊天msg", msg.User.GetNickName(), msg.Content)
+			d.viewers.Touch(msg.User, messageTime(msg.Common))
+			d.shopping.Chat(messageTime(msg.Common))
 			content := d.FilterMessage(msg.Content)
 			if content != "" {
-				model.InsertComments(liveId, content)
+				model.InsertComments(int(d.session), content)
+			}
+		},
+		OnMemberMessage: func(msg *douyin.MemberMessage) {
+			d.viewers.Enter(msg)
+		},
+		OnSocialMessage: func(msg *douyin.SocialMessage) {
+			d.viewers.Touch(msg.User, messageTime(msg.Common))
+			d.handleSocial(ParseSocialMessage(msg))
+		},
+		OnLiveShoppingMessage: func(msg *douyin.LiveShoppingMessage) {
+			d.saveProductEvents(d.shopping.Shopping(msg))
+		},
+		OnProductChangeMessage: func(msg *douyin.ProductChangeMessage) {
+			d.saveProductEvents(d.shopping.ProductChange(msg))
+		},
+		OnControlMessage: func(msg *douyin.ControlMessage) {
+			// status 3 表示直播结束，处理完本批消息后退出循环
+			if msg.Status == LiveStatusEnded {
+				d.isLiveClosed = false
+				log.Println("直播已结束，关闭ws链接")
 			}
+		},
+	}
+}
+
+// handleSocial 统计并保存关注、分享事件
+func (d *DouyinLive) handleSocial(event SocialEvent) {
+	var err error
+	switch e := event.(type) {
+	case *FollowEvent:
+		d.social.Follow(e)
+		err = model.InsertFollowEvent(&model.FollowEvent{
+			SessionId:   int(d.session),
+			MsgId:       e.MsgId,
+			UserId:      e.User.UserId,
+			SecUid:      e.User.SecUid,
+			NickName:    e.User.NickName,
+			FollowCount: e.FollowCount,
+			CreatedAt:   e.Time,
+		})
+	case *ShareEvent:
+		d.social.Share(e)
+		err = model.InsertShareEvent(&model.ShareEvent{
+			SessionId:   int(d.session),
+			MsgId:       e.MsgId,
+			UserId:      e.User.UserId,
+			SecUid:      e.User.SecUid,
+			NickName:    e.User.NickName,
+			ShareType:   e.ShareType,
+			ShareTarget: e.ShareTarget,
+			CreatedAt:   e.Time,
+		})
+	}
+	if err != nil {
+		log.Printf("保存关注/分享事件失败: %v\n", err)
+	}
+}
+
+// Social 返回本场直播的关注与分享统计器，开始处理前为 nil
+func (d *DouyinLive) Social() *SocialTracker {
+	d.stateMu.RLock()
+	defer d.stateMu.RUnlock()
+	return d.social
+}
+
+// Shopping 返回本场直播的商品时间线统计器，开始处理前为 nil
+func (d *DouyinLive) Shopping() *ShoppingTracker {
+	d.stateMu.RLock()
+	defer d.stateMu.RUnlock()
+	return d.shopping
+}
+
+// saveProductEvents 保存商品时间线事件
+func (d *DouyinLive) saveProductEvents(events []ProductEvent) {
+	for _, e := range events {
+		detail, _ := json.Marshal(e)
+		err := model.InsertProductEvent(&model.ProductEvent{
+			SessionId:   int(d.session),
+			Kind:        e.Kind,
+			PromotionId: e.PromotionId,
+			ExplainType: e.ExplainType,
+			MsgType:     e.MsgType,
+			CategoryId:  e.CategoryId,
+			Detail:      string(detail),
+			CreatedAt:   e.Time,
+		})
+		if err != nil {
+			log.Printf("保存商品事件失败: %v\n", err)
 		}
 	}
 }
 
-// Subscribe 订阅事件处理器
-func (d *DouyinLive) Subscribe(handler EventHandler) {
-	d.eventHandlers = append(d.eventHandlers, handler)
+// saveShopping 本场结束时保存商品讲解及弹幕量
+func (d *DouyinLive) saveShopping() {
+	d.shopping.Finish()
+	explanations := d.shopping.Explanations()
+	records := make([]model.ProductExplanation, 0, len(explanations))
+	for _, e := range explanations {
+		records = append(records, model.ProductExplanation{
+			SessionId:   int(d.session),
+			PromotionId: e.PromotionId,
+			ExplainType: e.ExplainType,
+			StartAt:     e.Start,
+			EndAt:       e.End,
+			Chats:       e.Chats,
+			ChatsBefore: e.ChatsBefore,
+		})
+	}
+	if err := model.InsertProductExplanations(records); err != nil {
+		log.Printf("保存商品讲解统计失败: %v\n", err)
+	}
 }
 
-func Close(roomId int) bool {
-	data := StopChanData{
-		RoomId: roomId,
+// saveSocial 本场结束时保存关注与分享统计
+func (d *DouyinLive) saveSocial() {
+	summary := d.social.Summary()
+	sharesByTarget, _ := json.Marshal(summary.SharesByTarget)
+	err := model.InsertSessionSocial(&model.SessionSocial{
+		SessionId:      int(d.session),
+		Follows:        summary.Follows,
+		FollowerGain:   summary.FollowerGain,
+		Shares:         summary.Shares,
+		SharesByTarget: string(sharesByTarget),
+	})
+	if err != nil {
+		log.Printf("保存关注与分享统计失败: %v\n", err)
 	}
+}
+
+// WebRid 直播间链接中的 ID
+func (d *DouyinLive) WebRid() WebRid {
+	return d.webRid
+}
+
+// RoomID 本场直播的 webcast room_id
+func (d *DouyinLive) RoomID() RoomID {
+	return d.roomID
+}
 
-	select {
-	case StopChan <- data:
-		// 发送成功
-		return true
-	case <-time.After(time.Second):
-		// 超时了，可能是因为通道阻塞
-		return false
+// Session 本服务分配的场次 ID
+func (d *DouyinLive) Session() SessionID {
+	d.stateMu.RLock()
+	defer d.stateMu.RUnlock()
+	return d.session
+}
+
+func (d *DouyinLive) setSession(session SessionID) {
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+	d.session = session
+}
+
+// RoomStats 直播间的运行统计
+type RoomStats struct {
+	StartedAt time.Time `json:"started_at"` // 开始处理的时间，未开始时为零值
+	Frames    int64     `json:"frames"`     // 收到的 PushFrame 数
+	Messages  int64     `json:"messages"`   // 处理的消息数
+}
+
+// Stats 返回直播间的运行统计，可并发调用
+func (d *DouyinLive) Stats() RoomStats {
+	stats := RoomStats{
+		Frames:   d.frames.Load(),
+		Messages: d.messages.Load(),
+	}
+	if startedAt := d.startedAt.Load(); startedAt != 0 {
+		stats.StartedAt = time.Unix(0, startedAt)
 	}
+	return stats
+}
+
+// Viewers 返回本场直播的观众统计器，开始处理前为 nil
+func (d *DouyinLive) Viewers() *ViewerTracker {
+	d.stateMu.RLock()
+	defer d.stateMu.RUnlock()
+	return d.viewers
+}
+
+// saveAudience 本场结束时保存观众统计，并将观众并入主播历史集合
+func (d *DouyinLive) saveAudience() {
+	summary := d.viewers.Summary()
+	d.viewers.Finish()
+	err := model.InsertSessionAudience(&model.SessionAudience{
+		SessionId:        int(d.session),
+		AnchorId:         string(d.webRid),
+		UniqueViewers:    summary.UniqueViewers,
+		ReturningViewers: summary.ReturningViewers,
+		PeakMemberCount:  summary.PeakMemberCount,
+		AvgDwellSeconds:  summary.AvgDwell.Seconds(),
+	})
+	if err != nil {
+		log.Printf("保存观众统计失败: %v\n", err)
+	}
+	if d.viewers.audience == nil {
+		return
+	}
+	if err := d.viewers.audience.Save(); err != nil {
+		log.Printf("保存主播历史观众失败: %v\n", err)
+	}
+}
+
+// Subscribe 订阅事件处理器
+func (d *DouyinLive) Subscribe(handler EventHandler) {
+	d.eventHandlers = append(d.eventHandlers, handler)
 }
 
 // 过滤消息
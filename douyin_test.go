@@ -1,16 +1,21 @@
 package douyinlive
 
 import (
+	"context"
 	"douyinlive/generated/douyin"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 	"log"
 	"testing"
+	"time"
 )
 
 func TestNewDouyinLive(t *testing.T) {
-	d, _ := NewDouyinLive("644826113301")
-	d.Subscribe(func(eventData *douyin.Message) {
+	d, err := NewDouyinLive("644826113301")
+	if err != nil {
+		t.Skipf("无法连接抖音: %v", err)
+	}
+	d.Subscribe(func(eventData *Event) {
 		if eventData.Method == WebcastChatMessage {
 			msg := &douyin.ChatMessage{}
 			proto.Unmarshal(eventData.Payload, msg)
@@ -19,6 +24,8 @@ func TestNewDouyinLive(t *testing.T) {
 		}
 	})
 
-	d.Start()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	d.Start(ctx, 0)
 
 }
@@ -0,0 +1,69 @@
+// Package dynproto 在运行时加载 .proto 文件，通过 dynamicpb 解析未编译进程序的消息类型
+package dynproto
+
+import (
+	"context"
+	"douyinlive/config"
+	"douyinlive/generated"
+	"fmt"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	// 确保内置的 douyin.proto 已注册，运行时的 .proto 可以 import 它
+	_ "douyinlive/generated/douyin"
+)
+
+// Compile 编译 .proto 文件，import 优先在 importPaths 中查找，其次使用已编译进程序的文件（如 douyin.proto）
+func Compile(files, importPaths []string) (*protoregistry.Files, error) {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(protocompile.CompositeResolver{
+			&protocompile.SourceResolver{ImportPaths: importPaths},
+			protocompile.ResolverFunc(func(path string) (protocompile.SearchResult, error) {
+				fd, err := protoregistry.GlobalFiles.FindFileByPath(path)
+				if err != nil {
+					return protocompile.SearchResult{}, err
+				}
+				return protocompile.SearchResult{Desc: fd}, nil
+			}),
+		}),
+	}
+	compiled, err := compiler.Compile(context.Background(), files...)
+	if err != nil {
+		return nil, fmt.Errorf("编译 .proto 失败: %w", err)
+	}
+	registry := new(protoregistry.Files)
+	for _, fd := range compiled {
+		if err := registry.RegisterFile(fd); err != nil {
+			return nil, err
+		}
+	}
+	return registry, nil
+}
+
+// Load 加载配置中的 .proto 文件，并将方法映射注册到 generated.MessageMap，返回注册的方法数量
+func Load(conf config.ProtoConf) (int, error) {
+	if len(conf.Files) == 0 {
+		return 0, nil
+	}
+	files, err := Compile(conf.Files, conf.ImportPaths)
+	if err != nil {
+		return 0, err
+	}
+	for _, mapping := range conf.Methods {
+		desc, err := files.FindDescriptorByName(protoreflect.FullName(mapping.Message))
+		if err != nil {
+			return 0, fmt.Errorf("未找到消息 %s: %w", mapping.Message, err)
+		}
+		md, ok := desc.(protoreflect.MessageDescriptor)
+		if !ok {
+			return 0, fmt.Errorf("%s 不是消息类型", mapping.Message)
+		}
+		generated.Register(mapping.Method, func() protoreflect.ProtoMessage {
+			return dynamicpb.NewMessage(md)
+		})
+	}
+	return len(conf.Methods), nil
+}
@@ -0,0 +1,59 @@
+package dynproto
+
+import (
+	"douyinlive/config"
+	"douyinlive/generated/douyin"
+	"douyinlive/utils"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const testProto = `syntax = "proto3";
+package ext;
+import "douyin.proto";
+
+message LotteryMessage {
+  Common common = 1;
+  string title = 2;
+  int64 prize_count = 3;
+}
+`
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ext.proto"), []byte(testProto), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	n, err := Load(config.ProtoConf{
+		Files:       []string{"ext.proto"},
+		ImportPaths: []string{dir},
+		Methods:     []config.MethodMapping{{Method: "WebcastLotteryMessage", Message: "ext.LotteryMessage"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("注册数量 = %d", n)
+	}
+
+	// 与 ext.LotteryMessage 字段编号一致的已知消息
+	payload, _ := proto.Marshal(&douyin.RoomMessage{Common: &douyin.Common{Method: "WebcastLotteryMessage"}, Content: "抽奖"})
+	msg, err := utils.MatchMethod("WebcastLotteryMessage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := proto.Unmarshal(payload, msg); err != nil {
+		t.Fatal(err)
+	}
+	out, err := protojson.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if title := msg.ProtoReflect().Get(msg.ProtoReflect().Descriptor().Fields().ByName("title")).String(); title != "抽奖" {
+		t.Fatalf("title = %q, json = %s", title, out)
+	}
+}
@@ -0,0 +1,677 @@
+// Code generated by cmd/msggen from protobuf/methods.txt. DO NOT EDIT.
+
+package generated
+
+import (
+	"douyinlive/generated/douyin"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var MessageMap = map[string]func() protoreflect.ProtoMessage{
+	"WebcastChatMessage":                 func() protoreflect.ProtoMessage { return &douyin.ChatMessage{} },
+	"WebcastGiftMessage":                 func() protoreflect.ProtoMessage { return &douyin.GiftMessage{} },
+	"WebcastLikeMessage":                 func() protoreflect.ProtoMessage { return &douyin.LikeMessage{} },
+	"WebcastMemberMessage":               func() protoreflect.ProtoMessage { return &douyin.MemberMessage{} },
+	"WebcastSocialMessage":               func() protoreflect.ProtoMessage { return &douyin.SocialMessage{} },
+	"WebcastRoomUserSeqMessage":          func() protoreflect.ProtoMessage { return &douyin.RoomUserSeqMessage{} },
+	"WebcastFansclubMessage":             func() protoreflect.ProtoMessage { return &douyin.FansclubMessage{} },
+	"WebcastControlMessage":              func() protoreflect.ProtoMessage { return &douyin.ControlMessage{} },
+	"WebcastEmojiChatMessage":            func() protoreflect.ProtoMessage { return &douyin.EmojiChatMessage{} },
+	"WebcastRoomStatsMessage":            func() protoreflect.ProtoMessage { return &douyin.RoomStatsMessage{} },
+	"WebcastRoomMessage":                 func() protoreflect.ProtoMessage { return &douyin.RoomMessage{} },
+	"WebcastRanklistHourEntranceMessage": func() protoreflect.ProtoMessage { return &douyin.RanklistHourEntranceMessage{} },
+	"WebcastRoomRankMessage":             func() protoreflect.ProtoMessage { return &douyin.RoomRankMessage{} },
+	"WebcastInRoomBannerMessage":         func() protoreflect.ProtoMessage { return &douyin.InRoomBannerMessage{} },
+	"WebcastRoomDataSyncMessage":         func() protoreflect.ProtoMessage { return &douyin.RoomDataSyncMessage{} },
+	"WebcastLuckyBoxTempStatusMessage":   func() protoreflect.ProtoMessage { return &douyin.LuckyBoxTempStatusMessage{} },
+	"WebcastDecorationModifyMethod":      func() protoreflect.ProtoMessage { return &douyin.DecorationUpdateMessage{} },
+	"WebcastLinkMicAudienceKtvMessage":   func() protoreflect.ProtoMessage { return &douyin.LinkMicAudienceKtvMessage{} },
+	"WebcastRoomStreamAdaptationMessage": func() protoreflect.ProtoMessage { return &douyin.RoomStreamAdaptationMessage{} },
+	"WebcastQuizAudienceStatusMessage":   func() protoreflect.ProtoMessage { return &douyin.QuizAudienceStatusMessage{} },
+	"WebcastHotChatMessage":              func() protoreflect.ProtoMessage { return &douyin.HotChatMessage{} },
+	"WebcastHotRoomMessage":              func() protoreflect.ProtoMessage { return &douyin.HotRoomMessage{} },
+	"WebcastAudioChatMessage":            func() protoreflect.ProtoMessage { return &douyin.AudioChatMessage{} },
+	"WebcastRoomNotifyMessage":           func() protoreflect.ProtoMessage { return &douyin.NotifyMessage{} },
+	"WebcastLuckyBoxMessage":             func() protoreflect.ProtoMessage { return &douyin.LuckyBoxMessage{} },
+	"WebcastUpdateFanTicketMessage":      func() protoreflect.ProtoMessage { return &douyin.UpdateFanTicketMessage{} },
+	"WebcastScreenChatMessage":           func() protoreflect.ProtoMessage { return &douyin.ScreenChatMessage{} },
+	"WebcastNotifyEffectMessage":         func() protoreflect.ProtoMessage { return &douyin.NotifyEffectMessage{} },
+	"WebcastBindingGiftMessage":          func() protoreflect.ProtoMessage { return &douyin.NotifyEffectMessage_BindingGiftMessage{} },
+	"WebcastTempStateAreaReachMessage":   func() protoreflect.ProtoMessage { return &douyin.TempStateAreaReachMessage{} },
+	"WebcastGrowthTaskMessage":           func() protoreflect.ProtoMessage { return &douyin.GrowthTaskMessage{} },
+	"WebcastGameCPBaseMessage":           func() protoreflect.ProtoMessage { return &douyin.GameCPBaseMessage{} },
+	"WebcastLiveShoppingMessage":         func() protoreflect.ProtoMessage { return &douyin.LiveShoppingMessage{} },
+	"WebcastProductChangeMessage":        func() protoreflect.ProtoMessage { return &douyin.ProductChangeMessage{} },
+}
+
+// Handlers 按方法分发的强类型处理函数，未设置的方法会被忽略
+type Handlers struct {
+	OnChatMessage                 func(msg *douyin.ChatMessage)
+	OnGiftMessage                 func(msg *douyin.GiftMessage)
+	OnLikeMessage                 func(msg *douyin.LikeMessage)
+	OnMemberMessage               func(msg *douyin.MemberMessage)
+	OnSocialMessage               func(msg *douyin.SocialMessage)
+	OnRoomUserSeqMessage          func(msg *douyin.RoomUserSeqMessage)
+	OnFansclubMessage             func(msg *douyin.FansclubMessage)
+	OnControlMessage              func(msg *douyin.ControlMessage)
+	OnEmojiChatMessage            func(msg *douyin.EmojiChatMessage)
+	OnRoomStatsMessage            func(msg *douyin.RoomStatsMessage)
+	OnRoomMessage                 func(msg *douyin.RoomMessage)
+	OnRanklistHourEntranceMessage func(msg *douyin.RanklistHourEntranceMessage)
+	OnRoomRankMessage             func(msg *douyin.RoomRankMessage)
+	OnInRoomBannerMessage         func(msg *douyin.InRoomBannerMessage)
+	OnRoomDataSyncMessage         func(msg *douyin.RoomDataSyncMessage)
+	OnLuckyBoxTempStatusMessage   func(msg *douyin.LuckyBoxTempStatusMessage)
+	OnDecorationModifyMethod      func(msg *douyin.DecorationUpdateMessage)
+	OnLinkMicAudienceKtvMessage   func(msg *douyin.LinkMicAudienceKtvMessage)
+	OnRoomStreamAdaptationMessage func(msg *douyin.RoomStreamAdaptationMessage)
+	OnQuizAudienceStatusMessage   func(msg *douyin.QuizAudienceStatusMessage)
+	OnHotChatMessage              func(msg *douyin.HotChatMessage)
+	OnHotRoomMessage              func(msg *douyin.HotRoomMessage)
+	OnAudioChatMessage            func(msg *douyin.AudioChatMessage)
+	OnRoomNotifyMessage           func(msg *douyin.NotifyMessage)
+	OnLuckyBoxMessage             func(msg *douyin.LuckyBoxMessage)
+	OnUpdateFanTicketMessage      func(msg *douyin.UpdateFanTicketMessage)
+	OnScreenChatMessage           func(msg *douyin.ScreenChatMessage)
+	OnNotifyEffectMessage         func(msg *douyin.NotifyEffectMessage)
+	OnBindingGiftMessage          func(msg *douyin.NotifyEffectMessage_BindingGiftMessage)
+	OnTempStateAreaReachMessage   func(msg *douyin.TempStateAreaReachMessage)
+	OnGrowthTaskMessage           func(msg *douyin.GrowthTaskMessage)
+	OnGameCPBaseMessage           func(msg *douyin.GameCPBaseMessage)
+	OnLiveShoppingMessage         func(msg *douyin.LiveShoppingMessage)
+	OnProductChangeMessage        func(msg *douyin.ProductChangeMessage)
+}
+
+// Dispatch 解码消息并调用对应的处理函数，handled 表示是否有处理函数接收了该消息
+func (h *Handlers) Dispatch(message *douyin.Message) (handled bool, err error) {
+	switch message.Method {
+	case "WebcastChatMessage":
+		if h.OnChatMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.ChatMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnChatMessage(msg)
+		return true, nil
+	case "WebcastGiftMessage":
+		if h.OnGiftMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.GiftMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnGiftMessage(msg)
+		return true, nil
+	case "WebcastLikeMessage":
+		if h.OnLikeMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.LikeMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnLikeMessage(msg)
+		return true, nil
+	case "WebcastMemberMessage":
+		if h.OnMemberMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.MemberMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnMemberMessage(msg)
+		return true, nil
+	case "WebcastSocialMessage":
+		if h.OnSocialMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.SocialMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnSocialMessage(msg)
+		return true, nil
+	case "WebcastRoomUserSeqMessage":
+		if h.OnRoomUserSeqMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.RoomUserSeqMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnRoomUserSeqMessage(msg)
+		return true, nil
+	case "WebcastFansclubMessage":
+		if h.OnFansclubMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.FansclubMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnFansclubMessage(msg)
+		return true, nil
+	case "WebcastControlMessage":
+		if h.OnControlMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.ControlMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnControlMessage(msg)
+		return true, nil
+	case "WebcastEmojiChatMessage":
+		if h.OnEmojiChatMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.EmojiChatMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnEmojiChatMessage(msg)
+		return true, nil
+	case "WebcastRoomStatsMessage":
+		if h.OnRoomStatsMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.RoomStatsMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnRoomStatsMessage(msg)
+		return true, nil
+	case "WebcastRoomMessage":
+		if h.OnRoomMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.RoomMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnRoomMessage(msg)
+		return true, nil
+	case "WebcastRanklistHourEntranceMessage":
+		if h.OnRanklistHourEntranceMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.RanklistHourEntranceMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnRanklistHourEntranceMessage(msg)
+		return true, nil
+	case "WebcastRoomRankMessage":
+		if h.OnRoomRankMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.RoomRankMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnRoomRankMessage(msg)
+		return true, nil
+	case "WebcastInRoomBannerMessage":
+		if h.OnInRoomBannerMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.InRoomBannerMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnInRoomBannerMessage(msg)
+		return true, nil
+	case "WebcastRoomDataSyncMessage":
+		if h.OnRoomDataSyncMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.RoomDataSyncMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnRoomDataSyncMessage(msg)
+		return true, nil
+	case "WebcastLuckyBoxTempStatusMessage":
+		if h.OnLuckyBoxTempStatusMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.LuckyBoxTempStatusMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnLuckyBoxTempStatusMessage(msg)
+		return true, nil
+	case "WebcastDecorationModifyMethod":
+		if h.OnDecorationModifyMethod == nil {
+			return false, nil
+		}
+		msg := &douyin.DecorationUpdateMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnDecorationModifyMethod(msg)
+		return true, nil
+	case "WebcastLinkMicAudienceKtvMessage":
+		if h.OnLinkMicAudienceKtvMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.LinkMicAudienceKtvMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnLinkMicAudienceKtvMessage(msg)
+		return true, nil
+	case "WebcastRoomStreamAdaptationMessage":
+		if h.OnRoomStreamAdaptationMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.RoomStreamAdaptationMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnRoomStreamAdaptationMessage(msg)
+		return true, nil
+	case "WebcastQuizAudienceStatusMessage":
+		if h.OnQuizAudienceStatusMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.QuizAudienceStatusMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnQuizAudienceStatusMessage(msg)
+		return true, nil
+	case "WebcastHotChatMessage":
+		if h.OnHotChatMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.HotChatMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnHotChatMessage(msg)
+		return true, nil
+	case "WebcastHotRoomMessage":
+		if h.OnHotRoomMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.HotRoomMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnHotRoomMessage(msg)
+		return true, nil
+	case "WebcastAudioChatMessage":
+		if h.OnAudioChatMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.AudioChatMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnAudioChatMessage(msg)
+		return true, nil
+	case "WebcastRoomNotifyMessage":
+		if h.OnRoomNotifyMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.NotifyMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnRoomNotifyMessage(msg)
+		return true, nil
+	case "WebcastLuckyBoxMessage":
+		if h.OnLuckyBoxMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.LuckyBoxMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnLuckyBoxMessage(msg)
+		return true, nil
+	case "WebcastUpdateFanTicketMessage":
+		if h.OnUpdateFanTicketMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.UpdateFanTicketMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnUpdateFanTicketMessage(msg)
+		return true, nil
+	case "WebcastScreenChatMessage":
+		if h.OnScreenChatMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.ScreenChatMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnScreenChatMessage(msg)
+		return true, nil
+	case "WebcastNotifyEffectMessage":
+		if h.OnNotifyEffectMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.NotifyEffectMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnNotifyEffectMessage(msg)
+		return true, nil
+	case "WebcastBindingGiftMessage":
+		if h.OnBindingGiftMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.NotifyEffectMessage_BindingGiftMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnBindingGiftMessage(msg)
+		return true, nil
+	case "WebcastTempStateAreaReachMessage":
+		if h.OnTempStateAreaReachMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.TempStateAreaReachMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnTempStateAreaReachMessage(msg)
+		return true, nil
+	case "WebcastGrowthTaskMessage":
+		if h.OnGrowthTaskMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.GrowthTaskMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnGrowthTaskMessage(msg)
+		return true, nil
+	case "WebcastGameCPBaseMessage":
+		if h.OnGameCPBaseMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.GameCPBaseMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnGameCPBaseMessage(msg)
+		return true, nil
+	case "WebcastLiveShoppingMessage":
+		if h.OnLiveShoppingMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.LiveShoppingMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnLiveShoppingMessage(msg)
+		return true, nil
+	case "WebcastProductChangeMessage":
+		if h.OnProductChangeMessage == nil {
+			return false, nil
+		}
+		msg := &douyin.ProductChangeMessage{}
+		if err := proto.Unmarshal(message.Payload, msg); err != nil {
+			return false, err
+		}
+		h.OnProductChangeMessage(msg)
+		return true, nil
+	}
+	return false, nil
+}
+
+// Handle 调用已解码消息对应的处理函数，返回是否有处理函数接收了该消息
+func (h *Handlers) Handle(method string, msg protoreflect.ProtoMessage) bool {
+	switch method {
+	case "WebcastChatMessage":
+		m, ok := msg.(*douyin.ChatMessage)
+		if !ok || h.OnChatMessage == nil {
+			return false
+		}
+		h.OnChatMessage(m)
+		return true
+	case "WebcastGiftMessage":
+		m, ok := msg.(*douyin.GiftMessage)
+		if !ok || h.OnGiftMessage == nil {
+			return false
+		}
+		h.OnGiftMessage(m)
+		return true
+	case "WebcastLikeMessage":
+		m, ok := msg.(*douyin.LikeMessage)
+		if !ok || h.OnLikeMessage == nil {
+			return false
+		}
+		h.OnLikeMessage(m)
+		return true
+	case "WebcastMemberMessage":
+		m, ok := msg.(*douyin.MemberMessage)
+		if !ok || h.OnMemberMessage == nil {
+			return false
+		}
+		h.OnMemberMessage(m)
+		return true
+	case "WebcastSocialMessage":
+		m, ok := msg.(*douyin.SocialMessage)
+		if !ok || h.OnSocialMessage == nil {
+			return false
+		}
+		h.OnSocialMessage(m)
+		return true
+	case "WebcastRoomUserSeqMessage":
+		m, ok := msg.(*douyin.RoomUserSeqMessage)
+		if !ok || h.OnRoomUserSeqMessage == nil {
+			return false
+		}
+		h.OnRoomUserSeqMessage(m)
+		return true
+	case "WebcastFansclubMessage":
+		m, ok := msg.(*douyin.FansclubMessage)
+		if !ok || h.OnFansclubMessage == nil {
+			return false
+		}
+		h.OnFansclubMessage(m)
+		return true
+	case "WebcastControlMessage":
+		m, ok := msg.(*douyin.ControlMessage)
+		if !ok || h.OnControlMessage == nil {
+			return false
+		}
+		h.OnControlMessage(m)
+		return true
+	case "WebcastEmojiChatMessage":
+		m, ok := msg.(*douyin.EmojiChatMessage)
+		if !ok || h.OnEmojiChatMessage == nil {
+			return false
+		}
+		h.OnEmojiChatMessage(m)
+		return true
+	case "WebcastRoomStatsMessage":
+		m, ok := msg.(*douyin.RoomStatsMessage)
+		if !ok || h.OnRoomStatsMessage == nil {
+			return false
+		}
+		h.OnRoomStatsMessage(m)
+		return true
+	case "WebcastRoomMessage":
+		m, ok := msg.(*douyin.RoomMessage)
+		if !ok || h.OnRoomMessage == nil {
+			return false
+		}
+		h.OnRoomMessage(m)
+		return true
+	case "WebcastRanklistHourEntranceMessage":
+		m, ok := msg.(*douyin.RanklistHourEntranceMessage)
+		if !ok || h.OnRanklistHourEntranceMessage == nil {
+			return false
+		}
+		h.OnRanklistHourEntranceMessage(m)
+		return true
+	case "WebcastRoomRankMessage":
+		m, ok := msg.(*douyin.RoomRankMessage)
+		if !ok || h.OnRoomRankMessage == nil {
+			return false
+		}
+		h.OnRoomRankMessage(m)
+		return true
+	case "WebcastInRoomBannerMessage":
+		m, ok := msg.(*douyin.InRoomBannerMessage)
+		if !ok || h.OnInRoomBannerMessage == nil {
+			return false
+		}
+		h.OnInRoomBannerMessage(m)
+		return true
+	case "WebcastRoomDataSyncMessage":
+		m, ok := msg.(*douyin.RoomDataSyncMessage)
+		if !ok || h.OnRoomDataSyncMessage == nil {
+			return false
+		}
+		h.OnRoomDataSyncMessage(m)
+		return true
+	case "WebcastLuckyBoxTempStatusMessage":
+		m, ok := msg.(*douyin.LuckyBoxTempStatusMessage)
+		if !ok || h.OnLuckyBoxTempStatusMessage == nil {
+			return false
+		}
+		h.OnLuckyBoxTempStatusMessage(m)
+		return true
+	case "WebcastDecorationModifyMethod":
+		m, ok := msg.(*douyin.DecorationUpdateMessage)
+		if !ok || h.OnDecorationModifyMethod == nil {
+			return false
+		}
+		h.OnDecorationModifyMethod(m)
+		return true
+	case "WebcastLinkMicAudienceKtvMessage":
+		m, ok := msg.(*douyin.LinkMicAudienceKtvMessage)
+		if !ok || h.OnLinkMicAudienceKtvMessage == nil {
+			return false
+		}
+		h.OnLinkMicAudienceKtvMessage(m)
+		return true
+	case "WebcastRoomStreamAdaptationMessage":
+		m, ok := msg.(*douyin.RoomStreamAdaptationMessage)
+		if !ok || h.OnRoomStreamAdaptationMessage == nil {
+			return false
+		}
+		h.OnRoomStreamAdaptationMessage(m)
+		return true
+	case "WebcastQuizAudienceStatusMessage":
+		m, ok := msg.(*douyin.QuizAudienceStatusMessage)
+		if !ok || h.OnQuizAudienceStatusMessage == nil {
+			return false
+		}
+		h.OnQuizAudienceStatusMessage(m)
+		return true
+	case "WebcastHotChatMessage":
+		m, ok := msg.(*douyin.HotChatMessage)
+		if !ok || h.OnHotChatMessage == nil {
+			return false
+		}
+		h.OnHotChatMessage(m)
+		return true
+	case "WebcastHotRoomMessage":
+		m, ok := msg.(*douyin.HotRoomMessage)
+		if !ok || h.OnHotRoomMessage == nil {
+			return false
+		}
+		h.OnHotRoomMessage(m)
+		return true
+	case "WebcastAudioChatMessage":
+		m, ok := msg.(*douyin.AudioChatMessage)
+		if !ok || h.OnAudioChatMessage == nil {
+			return false
+		}
+		h.OnAudioChatMessage(m)
+		return true
+	case "WebcastRoomNotifyMessage":
+		m, ok := msg.(*douyin.NotifyMessage)
+		if !ok || h.OnRoomNotifyMessage == nil {
+			return false
+		}
+		h.OnRoomNotifyMessage(m)
+		return true
+	case "WebcastLuckyBoxMessage":
+		m, ok := msg.(*douyin.LuckyBoxMessage)
+		if !ok || h.OnLuckyBoxMessage == nil {
+			return false
+		}
+		h.OnLuckyBoxMessage(m)
+		return true
+	case "WebcastUpdateFanTicketMessage":
+		m, ok := msg.(*douyin.UpdateFanTicketMessage)
+		if !ok || h.OnUpdateFanTicketMessage == nil {
+			return false
+		}
+		h.OnUpdateFanTicketMessage(m)
+		return true
+	case "WebcastScreenChatMessage":
+		m, ok := msg.(*douyin.ScreenChatMessage)
+		if !ok || h.OnScreenChatMessage == nil {
+			return false
+		}
+		h.OnScreenChatMessage(m)
+		return true
+	case "WebcastNotifyEffectMessage":
+		m, ok := msg.(*douyin.NotifyEffectMessage)
+		if !ok || h.OnNotifyEffectMessage == nil {
+			return false
+		}
+		h.OnNotifyEffectMessage(m)
+		return true
+	case "WebcastBindingGiftMessage":
+		m, ok := msg.(*douyin.NotifyEffectMessage_BindingGiftMessage)
+		if !ok || h.OnBindingGiftMessage == nil {
+			return false
+		}
+		h.OnBindingGiftMessage(m)
+		return true
+	case "WebcastTempStateAreaReachMessage":
+		m, ok := msg.(*douyin.TempStateAreaReachMessage)
+		if !ok || h.OnTempStateAreaReachMessage == nil {
+			return false
+		}
+		h.OnTempStateAreaReachMessage(m)
+		return true
+	case "WebcastGrowthTaskMessage":
+		m, ok := msg.(*douyin.GrowthTaskMessage)
+		if !ok || h.OnGrowthTaskMessage == nil {
+			return false
+		}
+		h.OnGrowthTaskMessage(m)
+		return true
+	case "WebcastGameCPBaseMessage":
+		m, ok := msg.(*douyin.GameCPBaseMessage)
+		if !ok || h.OnGameCPBaseMessage == nil {
+			return false
+		}
+		h.OnGameCPBaseMessage(m)
+		return true
+	case "WebcastLiveShoppingMessage":
+		m, ok := msg.(*douyin.LiveShoppingMessage)
+		if !ok || h.OnLiveShoppingMessage == nil {
+			return false
+		}
+		h.OnLiveShoppingMessage(m)
+		return true
+	case "WebcastProductChangeMessage":
+		m, ok := msg.(*douyin.ProductChangeMessage)
+		if !ok || h.OnProductChangeMessage == nil {
+			return false
+		}
+		h.OnProductChangeMessage(m)
+		return true
+	}
+	return false
+}
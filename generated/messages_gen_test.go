@@ -0,0 +1,60 @@
+package generated
+
+import (
+	"douyinlive/generated/douyin"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDispatch(t *testing.T) {
+	chat, _ := proto.Marshal(&douyin.ChatMessage{Content: "你好"})
+	binding, _ := proto.Marshal(&douyin.NotifyEffectMessage_BindingGiftMessage{Common: &douyin.Common{MsgId: 7}})
+
+	var content string
+	var msgId uint64
+	h := &Handlers{
+		OnChatMessage:        func(msg *douyin.ChatMessage) { content = msg.Content },
+		OnBindingGiftMessage: func(msg *douyin.NotifyEffectMessage_BindingGiftMessage) { msgId = msg.Common.GetMsgId() },
+	}
+	if handled, err := h.Dispatch(&douyin.Message{Method: "WebcastChatMessage", Payload: chat}); !handled || err != nil || content != "你好" {
+		t.Fatalf("分发弹幕失败: %v %v %q", handled, err, content)
+	}
+	if handled, err := h.Dispatch(&douyin.Message{Method: "WebcastBindingGiftMessage", Payload: binding}); !handled || err != nil || msgId != 7 {
+		t.Fatalf("分发嵌套消息失败: %v %v %d", handled, err, msgId)
+	}
+	if handled, err := h.Dispatch(&douyin.Message{Method: "WebcastGiftMessage", Payload: chat}); handled || err != nil {
+		t.Fatalf("未设置处理函数时应忽略: %v %v", handled, err)
+	}
+	if handled, err := h.Dispatch(&douyin.Message{Method: "WebcastUnknownMessage"}); handled || err != nil {
+		t.Fatalf("未知方法应忽略: %v %v", handled, err)
+	}
+	if handled, err := h.Dispatch(&douyin.Message{Method: "WebcastChatMessage", Payload: []byte{0x0a, 0xff}}); handled || err == nil {
+		t.Fatalf("解码失败应返回错误: %v %v", handled, err)
+	}
+}
+
+func TestMessageMap(t *testing.T) {
+	for method, newMessage := range MessageMap {
+		if newMessage() == nil {
+			t.Fatalf("%s 没有对应的消息", method)
+		}
+	}
+	if _, ok := MessageMap["WebcastDecorationModifyMethod"]().(*douyin.DecorationUpdateMessage); !ok {
+		t.Fatal("WebcastDecorationModifyMethod 应解码为 DecorationUpdateMessage")
+	}
+}
+
+func TestHandle(t *testing.T) {
+	var content string
+	h := &Handlers{OnChatMessage: func(msg *douyin.ChatMessage) { content = msg.Content }}
+	if !h.Handle("WebcastChatMessage", &douyin.ChatMessage{Content: "你好"}) || content != "你好" {
+		t.Fatalf("处理已解码的弹幕失败: %q", content)
+	}
+	if h.Handle("WebcastChatMessage", &douyin.GiftMessage{}) {
+		t.Fatal("消息类型与方法不符时应忽略")
+	}
+	if h.Handle("WebcastGiftMessage", &douyin.GiftMessage{}) || h.Handle("WebcastUnknownMessage", nil) {
+		t.Fatal("未设置处理函数或未知方法时应忽略")
+	}
+}
@@ -0,0 +1,12 @@
+package generated
+
+import (
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Register 在运行时注册方法与消息的映射，已存在的方法会被覆盖
+//
+// 只能在开始处理直播间消息之前调用，MessageMap 本身没有加锁。
+func Register(method string, createMessage func() protoreflect.ProtoMessage) {
+	MessageMap[method] = createMessage
+}
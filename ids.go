@@ -0,0 +1,10 @@
+package douyinlive
+
+// WebRid 直播间链接中的 ID，如 live.douyin.com/644826113301 中的 644826113301，同一主播长期不变
+type WebRid string
+
+// RoomID 抖音内部的 webcast room_id，每场直播都不同，用于建立 ws 连接
+type RoomID string
+
+// SessionID 本服务为一次抓取分配的场次 ID，入库的数据通过它关联
+type SessionID int
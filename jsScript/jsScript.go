@@ -2,8 +2,13 @@ package jsScript
 
 import (
 	_ "embed"
-	"github.com/dop251/goja"
+	"errors"
+	"fmt"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/dop251/goja"
 )
 
 // 嵌入的 JavaScript 文件来源于开源项目，感谢贡献者们的努力
@@ -14,15 +19,23 @@ var jsScript string
 var (
 	vm       *goja.Runtime
 	fGetSign func(string) string
-	mu       sync.Mutex
+	// loadErr 最近一次 LoadGoja 的错误，失败后直播间无法签名
+	loadErr error
+	mu      sync.Mutex
 )
 
 // LoadGoja 加载 JavaScript 到 Goja 运行时中，并设置必要的环境
 func LoadGoja(ua string) error {
 	mu.Lock()
 	defer mu.Unlock()
+	vm, fGetSign, loadErr = newSigner(ua)
+	return loadErr
+}
+
+// newSigner 创建加载了签名脚本的 Goja 运行时，返回导出的 get_sign 函数
+func newSigner(ua string) (*goja.Runtime, func(string) string, error) {
 	// 创建一个新的 Goja VM 实例
-	vm = goja.New()
+	vm := goja.New()
 
 	// 构建 JavaScript 环境，模拟浏览器的 navigator 和 window 对象
 	jsdom := `
@@ -35,11 +48,15 @@ func LoadGoja(ua string) error {
 
 	// 运行 JavaScript 环境设置和嵌入的 JavaScript 代码
 	if _, err := vm.RunString(jsdom + jsScript); err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	// 将 JavaScript 函数 get_sign 导出为 Go 函数 fGetSign
-	return vm.ExportTo(vm.Get("get_sign"), &fGetSign)
+	// 将 JavaScript 函数 get_sign 导出为 Go 函数
+	var getSign func(string) string
+	if err := vm.ExportTo(vm.Get("get_sign"), &getSign); err != nil {
+		return nil, nil, err
+	}
+	return vm, getSign, nil
 }
 
 // ExecuteJS 执行 JavaScript 中的 get_sign 函数
@@ -48,3 +65,61 @@ func ExecuteJS(signature string) string {
 	defer mu.Unlock()
 	return fGetSign(signature)
 }
+
+// checkUserAgent 尚未加载签名运行时时自检使用的 UA
+const checkUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36"
+
+// checkTTL 未加载签名运行时时自检结果的缓存时间
+const checkTTL = time.Minute
+
+var (
+	checkMu  sync.Mutex
+	checkErr error
+	checkAt  time.Time
+)
+
+// Check 检查签名能否生成
+//
+// 已调用 LoadGoja 时检查直播间实际使用的运行时；否则在独立的运行时中加载脚本并签名一次，结果缓存 checkTTL
+func Check() error {
+	mu.Lock()
+	if loadErr != nil {
+		err := loadErr
+		mu.Unlock()
+		return fmt.Errorf("加载签名脚本失败: %w", err)
+	}
+	if fGetSign != nil {
+		defer mu.Unlock()
+		return trySign(fGetSign)
+	}
+	mu.Unlock()
+
+	checkMu.Lock()
+	defer checkMu.Unlock()
+	if checkAt.IsZero() || time.Since(checkAt) > checkTTL {
+		checkErr, checkAt = selfTest(), time.Now()
+	}
+	return checkErr
+}
+
+func selfTest() error {
+	_, getSign, err := newSigner(checkUserAgent)
+	if err != nil {
+		return fmt.Errorf("加载签名脚本失败: %w", err)
+	}
+	return trySign(getSign)
+}
+
+// trySign 对固定的输入签名一次，检查结果是否为空
+func trySign(getSign func(string) string) (err error) {
+	// 脚本异常时导出的函数会 panic
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("签名脚本执行失败: %v", r)
+		}
+	}()
+	if getSign(strings.Repeat("0", 32)) == "" {
+		return errors.New("签名脚本返回空签名")
+	}
+	return nil
+}
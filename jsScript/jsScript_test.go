@@ -0,0 +1,44 @@
+package jsScript
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	if err := Check(); err != nil {
+		t.Fatalf("签名脚本自检失败: %v", err)
+	}
+}
+
+func TestCheckLiveSigner(t *testing.T) {
+	defer func() {
+		vm, fGetSign, loadErr = nil, nil, nil
+	}()
+	if err := LoadGoja(checkUserAgent); err != nil {
+		t.Fatal(err)
+	}
+	if err := Check(); err != nil {
+		t.Fatalf("签名运行时可用时检查应通过: %v", err)
+	}
+
+	// 直播间使用的运行时损坏时应立即反映，不受自检缓存影响
+	mu.Lock()
+	fGetSign = func(string) string { return "" }
+	mu.Unlock()
+	if err := Check(); err == nil {
+		t.Fatal("签名为空时检查应失败")
+	}
+	mu.Lock()
+	fGetSign = func(string) string { panic("broken") }
+	mu.Unlock()
+	if err := Check(); err == nil {
+		t.Fatal("签名脚本 panic 时检查应失败")
+	}
+	mu.Lock()
+	loadErr = errors.New("broken")
+	mu.Unlock()
+	if err := Check(); err == nil {
+		t.Fatal("加载失败时检查应失败")
+	}
+}
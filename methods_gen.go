@@ -0,0 +1,40 @@
+// Code generated by cmd/msggen from protobuf/methods.txt. DO NOT EDIT.
+
+package douyinlive
+
+const (
+	WebcastChatMessage                 = "WebcastChatMessage"
+	WebcastGiftMessage                 = "WebcastGiftMessage"
+	WebcastLikeMessage                 = "WebcastLikeMessage"
+	WebcastMemberMessage               = "WebcastMemberMessage"
+	WebcastSocialMessage               = "WebcastSocialMessage"
+	WebcastRoomUserSeqMessage          = "WebcastRoomUserSeqMessage"
+	WebcastFansclubMessage             = "WebcastFansclubMessage"
+	WebcastControlMessage              = "WebcastControlMessage"
+	WebcastEmojiChatMessage            = "WebcastEmojiChatMessage"
+	WebcastRoomStatsMessage            = "WebcastRoomStatsMessage"
+	WebcastRoomMessage                 = "WebcastRoomMessage"
+	WebcastRanklistHourEntranceMessage = "WebcastRanklistHourEntranceMessage"
+	WebcastRoomRankMessage             = "WebcastRoomRankMessage"
+	WebcastInRoomBannerMessage         = "WebcastInRoomBannerMessage"
+	WebcastRoomDataSyncMessage         = "WebcastRoomDataSyncMessage"
+	WebcastLuckyBoxTempStatusMessage   = "WebcastLuckyBoxTempStatusMessage"
+	WebcastDecorationModifyMethod      = "WebcastDecorationModifyMethod"
+	WebcastLinkMicAudienceKtvMessage   = "WebcastLinkMicAudienceKtvMessage"
+	WebcastRoomStreamAdaptationMessage = "WebcastRoomStreamAdaptationMessage"
+	WebcastQuizAudienceStatusMessage   = "WebcastQuizAudienceStatusMessage"
+	WebcastHotChatMessage              = "WebcastHotChatMessage"
+	WebcastHotRoomMessage              = "WebcastHotRoomMessage"
+	WebcastAudioChatMessage            = "WebcastAudioChatMessage"
+	WebcastRoomNotifyMessage           = "WebcastRoomNotifyMessage"
+	WebcastLuckyBoxMessage             = "WebcastLuckyBoxMessage"
+	WebcastUpdateFanTicketMessage      = "WebcastUpdateFanTicketMessage"
+	WebcastScreenChatMessage           = "WebcastScreenChatMessage"
+	WebcastNotifyEffectMessage         = "WebcastNotifyEffectMessage"
+	WebcastBindingGiftMessage          = "WebcastBindingGiftMessage"
+	WebcastTempStateAreaReachMessage   = "WebcastTempStateAreaReachMessage"
+	WebcastGrowthTaskMessage           = "WebcastGrowthTaskMessage"
+	WebcastGameCPBaseMessage           = "WebcastGameCPBaseMessage"
+	WebcastLiveShoppingMessage         = "WebcastLiveShoppingMessage"
+	WebcastProductChangeMessage        = "WebcastProductChangeMessage"
+)
@@ -0,0 +1,44 @@
+package douyinlive
+
+import (
+	"douyinlive/generated"
+	"douyinlive/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// 直播间连接的指标，room 标签是否生效由 metrics.SetPerRoom 控制
+var (
+	messagesReceived = metrics.NewRoomCounterVec("douyinlive_messages_received_total",
+		"收到的直播间消息数", "method")
+	decodeFailures = metrics.NewRoomCounterVec("douyinlive_decode_failures_total",
+		"解码失败次数，stage 为 frame、gzip、response 或 message", "stage")
+	acksSent = metrics.NewRoomCounterVec("douyinlive_acks_sent_total",
+		"发送的 ack 帧数")
+	signDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name: "douyinlive_sign_duration_seconds",
+		Help: "jsScript 计算 websocket 签名的耗时",
+	})
+)
+
+// 解码失败的阶段
+const (
+	stageFrame    = "frame"    // PushFrame
+	stageGzip     = "gzip"     // 解压 payload
+	stageResponse = "response" // Response
+	stageMessage  = "message"  // 具体消息类型
+)
+
+// methodLabel 消息类型标签，未注册的类型统一记为 unknown，避免标签取值无限增长
+func methodLabel(method string) string {
+	if _, ok := generated.MessageMap[method]; ok {
+		return method
+	}
+	return "unknown"
+}
+
+// roomLabel 当前直播间的 room 标签
+func (d *DouyinLive) roomLabel() string {
+	return metrics.Room(string(d.webRid))
+}
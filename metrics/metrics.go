@@ -0,0 +1,66 @@
+// Package metrics 服务指标的公共设置，指标本身使用 prometheus/client_golang 定义并注册到默认 Registry
+//
+// 标签取值必须是有限集合；直播间标签可通过 SetPerRoom 关闭，关闭后 Room 返回空字符串，
+// 同一指标的各直播间数据合并为一个序列。开启时直播间停止后应调用 DeleteRoom 删除其序列
+package metrics
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// RoomLabel 直播间标签名
+const RoomLabel = "room"
+
+var (
+	perRoom atomic.Bool
+
+	roomVecsMu sync.Mutex
+	// roomVecs 带直播间标签的指标，DeleteRoom 时从中删除对应序列
+	roomVecs []*prometheus.MetricVec
+)
+
+// Handler 输出默认 Registry 中指标的 HTTP 处理器
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// SetPerRoom 是否按直播间区分指标，直播间较多时关闭以控制序列数量
+func SetPerRoom(enabled bool) {
+	perRoom.Store(enabled)
+}
+
+// Room 直播间标签的取值，未开启按直播间区分时为空
+func Room(webRid string) string {
+	if !perRoom.Load() {
+		return ""
+	}
+	return webRid
+}
+
+// NewRoomCounterVec 创建并注册带有 room 标签的计数器，labels 不需要包含 room
+func NewRoomCounterVec(name, help string, labels ...string) *prometheus.CounterVec {
+	vec := prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, append(labels, RoomLabel))
+	prometheus.MustRegister(vec)
+	roomVecsMu.Lock()
+	roomVecs = append(roomVecs, vec.MetricVec)
+	roomVecsMu.Unlock()
+	return vec
+}
+
+// DeleteRoom 删除直播间的全部序列，未开启按直播间区分时不做处理，避免删除合并后的序列
+func DeleteRoom(webRid string) {
+	room := Room(webRid)
+	if room == "" {
+		return
+	}
+	roomVecsMu.Lock()
+	defer roomVecsMu.Unlock()
+	for _, vec := range roomVecs {
+		vec.DeletePartialMatch(prometheus.Labels{RoomLabel: room})
+	}
+}
@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestDeleteRoom(t *testing.T) {
+	defer SetPerRoom(false)
+	messages := NewRoomCounterVec("test_messages_total", "消息数", "method")
+
+	messages.WithLabelValues("WebcastChatMessage", Room("644826113301")).Inc()
+	SetPerRoom(true)
+	messages.WithLabelValues("WebcastChatMessage", Room("644826113301")).Inc()
+	messages.WithLabelValues("WebcastGiftMessage", Room("644826113301")).Inc()
+	messages.WithLabelValues("WebcastChatMessage", Room("23020419981")).Inc()
+	if n := testutil.CollectAndCount(messages); n != 4 {
+		t.Fatalf("期望 4 个序列，得到 %d", n)
+	}
+
+	DeleteRoom("644826113301")
+	if n := testutil.CollectAndCount(messages); n != 2 {
+		t.Fatalf("删除直播间后期望 2 个序列，得到 %d", n)
+	}
+	// 未开启按直播间区分时不删除合并后的序列
+	SetPerRoom(false)
+	DeleteRoom("23020419981")
+	if n := testutil.CollectAndCount(messages); n != 2 {
+		t.Fatalf("未开启按直播间区分时不应删除序列，得到 %d", n)
+	}
+}
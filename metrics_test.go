@@ -0,0 +1,27 @@
+package douyinlive
+
+import (
+	"douyinlive/archive"
+	"douyinlive/generated/douyin"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestDecodeFailuresAllMethods(t *testing.T) {
+	d := NewReplayLive(archive.Header{WebRid: "644826113301"})
+	d.viewers = NewViewerTracker(nil)
+	d.social = NewSocialTracker()
+	d.shopping = NewShoppingTracker()
+	failures := decodeFailures.WithLabelValues(stageMessage, d.roomLabel())
+	before := testutil.ToFloat64(failures)
+	invalid := []byte{0x0a, 0xff}
+	d.ProcessingMessage(&douyin.Response{MessagesList: []*douyin.Message{
+		{Method: WebcastChatMessage, Payload: invalid},      // 有内部处理函数
+		{Method: WebcastGiftMessage, Payload: invalid},      // 已注册但没有内部处理函数
+		{Method: "WebcastUnknownMessage", Payload: invalid}, // 未注册，不解码
+	}})
+	if got := testutil.ToFloat64(failures) - before; got != 2 {
+		t.Fatalf("期望 2 次解码失败，得到 %v", got)
+	}
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"douyinlive/database"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AnchorAudience 主播历史观众集合，用于跨场次识别回访观众
+type AnchorAudience struct {
+	AnchorId  string    `json:"anchor_id" gorm:"primaryKey"`
+	Mode      string    `json:"mode"`
+	Sessions  int       `json:"sessions"`
+	Data      []byte    `json:"-"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SessionAudience 单场直播的观众统计
+type SessionAudience struct {
+	SessionId        int     `json:"session_id" gorm:"column:live_id"`
+	AnchorId         string  `json:"anchor_id"`
+	UniqueViewers    int     `json:"unique_viewers"`
+	ReturningViewers int     `json:"returning_viewers"`
+	PeakMemberCount  int64   `json:"peak_member_count"`
+	AvgDwellSeconds  float64 `json:"avg_dwell_seconds"`
+}
+
+// GetAnchorAudience 查询主播历史观众集合，不存在时返回 nil
+func GetAnchorAudience(anchorId string) (*AnchorAudience, error) {
+	if !database.Enabled() {
+		return nil, nil
+	}
+	var audience AnchorAudience
+	err := database.DB.Table("anchor_audiences").Where("anchor_id = ?", anchorId).First(&audience).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &audience, nil
+}
+
+// SaveAnchorAudience 保存主播历史观众集合
+func SaveAnchorAudience(audience *AnchorAudience) error {
+	if !database.Enabled() {
+		return nil
+	}
+	audience.UpdatedAt = time.Now()
+	return database.DB.Table("anchor_audiences").Save(audience).Error
+}
+
+// InsertSessionAudience 保存单场直播的观众统计
+func InsertSessionAudience(audience *SessionAudience) error {
+	if !database.Enabled() {
+		return nil
+	}
+	return database.DB.Table("session_audiences").Create(audience).Error
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"douyinlive/database"
+
+	"gorm.io/gorm"
+)
+
+// tables 表名与对应的结构，与 MySQL 中的表名一致
+var tables = []struct {
+	name  string
+	model interface{}
+}{
+	{"comments", &Comment{}},
+	{"live_sessions", &LiveSession{}},
+	{"anchor_audiences", &AnchorAudience{}},
+	{"session_audiences", &SessionAudience{}},
+	{"follow_events", &FollowEvent{}},
+	{"share_events", &ShareEvent{}},
+	{"session_socials", &SessionSocial{}},
+	{"product_events", &ProductEvent{}},
+	{"product_explanations", &ProductExplanation{}},
+}
+
+// Migrate 创建缺少的表并补齐缺少的列，未连接数据库时不做处理
+//
+// 已有的列不做修改，MySQL 中已建好的 comments 等表保持原样，只补上新版本增加的表和列
+func Migrate() error {
+	if !database.Enabled() {
+		return nil
+	}
+	for _, table := range tables {
+		if err := migrateTable(table.name, table.model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func migrateTable(name string, model interface{}) error {
+	migrator := database.DB.Table(name).Migrator()
+	if !migrator.HasTable(name) {
+		return migrator.CreateTable(model)
+	}
+	stmt := &gorm.Statement{DB: database.DB}
+	if err := stmt.ParseWithSpecialTableName(model, name); err != nil {
+		return err
+	}
+	for _, column := range stmt.Schema.DBNames {
+		if migrator.HasColumn(model, column) {
+			continue
+		}
+		if err := migrator.AddColumn(model, column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,14 +3,17 @@ package model
 import "douyinlive/database"
 
 type Comment struct {
-	LiveId  int    `json:"live_id"`
-	Content string `json:"content"`
+	SessionId int    `json:"session_id" gorm:"column:live_id"`
+	Content   string `json:"content"`
 }
 
-func InsertComments(liveId int, content string) {
+func InsertComments(sessionId int, content string) {
+	if !database.Enabled() {
+		return
+	}
 	comment := Comment{
-		LiveId:  liveId,
-		Content: content,
+		SessionId: sessionId,
+		Content:   content,
 	}
 	database.DB.Table("comments").Create(&comment)
 }
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"douyinlive/database"
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLite(t *testing.T) {
+	if err := database.InitSQLite(filepath.Join(t.TempDir(), "douyinlive.db")); err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	if err := Migrate(); err != nil {
+		t.Fatal(err)
+	}
+
+	session := &LiveSession{WebRid: "644826113301", RoomId: "7383731312643626035", StartedBy: "test"}
+	if err := CreateLiveSession(session); err != nil || session.Id == 0 {
+		t.Fatalf("创建场次失败: %+v %v", session, err)
+	}
+	InsertComments(session.Id, "你好")
+	if err := FinishLiveSession(session.Id); err != nil {
+		t.Fatal(err)
+	}
+	var comments []Comment
+	if err := database.DB.Table("comments").Find(&comments).Error; err != nil || len(comments) != 1 || comments[0].SessionId != session.Id {
+		t.Fatalf("弹幕入库错误: %+v %v", comments, err)
+	}
+
+	if audience, err := GetAnchorAudience("1"); audience != nil || err != nil {
+		t.Fatalf("不存在的主播应返回 nil: %+v %v", audience, err)
+	}
+	for i := 1; i <= 2; i++ {
+		if err := SaveAnchorAudience(&AnchorAudience{AnchorId: "1", Sessions: i, Data: []byte{1, 2}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	audience, err := GetAnchorAudience("1")
+	if err != nil || audience.Sessions != 2 {
+		t.Fatalf("主播观众保存错误: %+v %v", audience, err)
+	}
+}
+
+func TestMigrateLegacyTables(t *testing.T) {
+	if err := database.InitSQLite(filepath.Join(t.TempDir(), "douyinlive.db")); err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	// 旧版本的 live_sessions 没有 started_by，comments 由 MySQL 建表语句创建
+	for _, sql := range []string{
+		"CREATE TABLE live_sessions (id INTEGER PRIMARY KEY AUTOINCREMENT, web_rid TEXT, room_id TEXT, started_at DATETIME, ended_at DATETIME)",
+		"CREATE TABLE comments (live_id INTEGER, content VARCHAR(255))",
+	} {
+		if err := database.DB.Exec(sql).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	migrator := database.DB.Migrator()
+	if !migrator.HasColumn("live_sessions", "started_by") {
+		t.Fatal("live_sessions 应补上 started_by 列")
+	}
+	if !migrator.HasTable("follow_events") || !migrator.HasTable("product_explanations") {
+		t.Fatal("应创建缺少的表")
+	}
+	var ddl string
+	database.DB.Raw("SELECT sql FROM sqlite_master WHERE name = 'comments'").Scan(&ddl)
+	if ddl != "CREATE TABLE comments (live_id INTEGER, content VARCHAR(255))" {
+		t.Fatalf("已有的表不应修改: %s", ddl)
+	}
+
+	session := &LiveSession{WebRid: "644826113301", StartedBy: "watchlist"}
+	if err := CreateLiveSession(session); err != nil || session.Id == 0 {
+		t.Fatalf("创建场次失败: %+v %v", session, err)
+	}
+	var saved LiveSession
+	if err := database.DB.Table("live_sessions").First(&saved, session.Id).Error; err != nil || saved.StartedBy != "watchlist" {
+		t.Fatalf("started_by 未保存: %+v %v", saved, err)
+	}
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"douyinlive/database"
+	"time"
+)
+
+// ProductEvent 商品时间线事件
+type ProductEvent struct {
+	SessionId   int       `json:"session_id" gorm:"column:live_id"`
+	Kind        string    `json:"kind"`
+	PromotionId int64     `json:"promotion_id"`
+	ExplainType int64     `json:"explain_type"`
+	MsgType     int32     `json:"msg_type"`
+	CategoryId  int32     `json:"category_id"`
+	Detail      string    `json:"detail"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ProductExplanation 单次商品讲解及其期间的弹幕量
+type ProductExplanation struct {
+	SessionId   int       `json:"session_id" gorm:"column:live_id"`
+	PromotionId int64     `json:"promotion_id"`
+	ExplainType int64     `json:"explain_type"`
+	StartAt     time.Time `json:"start_at"`
+	EndAt       time.Time `json:"end_at"`
+	Chats       int       `json:"chats"`
+	ChatsBefore int       `json:"chats_before"`
+}
+
+func InsertProductEvent(event *ProductEvent) error {
+	if !database.Enabled() {
+		return nil
+	}
+	return database.DB.Table("product_events").Create(event).Error
+}
+
+func InsertProductExplanations(explanations []ProductExplanation) error {
+	if !database.Enabled() {
+		return nil
+	}
+	if len(explanations) == 0 {
+		return nil
+	}
+	return database.DB.Table("product_explanations").Create(&explanations).Error
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"douyinlive/database"
+	"time"
+)
+
+// LiveSession 一次抓取的场次，Id 即各表中的 live_id
+type LiveSession struct {
+	Id        int        `json:"id" gorm:"primaryKey;autoIncrement"`
+	WebRid    string     `json:"web_rid"`
+	RoomId    string     `json:"room_id"`
+	StartedBy string     `json:"started_by"` // 开始抓取的调用方
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at"`
+}
+
+// CreateLiveSession 创建场次并回填自增 Id，未连接数据库时不做处理
+func CreateLiveSession(session *LiveSession) error {
+	if !database.Enabled() {
+		return nil
+	}
+	session.StartedAt = time.Now()
+	return database.DB.Table("live_sessions").Create(session).Error
+}
+
+// FinishLiveSession 记录场次结束时间
+func FinishLiveSession(id int) error {
+	if !database.Enabled() {
+		return nil
+	}
+	return database.DB.Table("live_sessions").Where("id = ?", id).Update("ended_at", time.Now()).Error
+}
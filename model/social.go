@@ -0,0 +1,59 @@
+package model
+
+import (
+	"douyinlive/database"
+	"time"
+)
+
+// FollowEvent 关注事件
+type FollowEvent struct {
+	SessionId   int       `json:"session_id" gorm:"column:live_id"`
+	MsgId       uint64    `json:"msg_id"`
+	UserId      uint64    `json:"user_id"`
+	SecUid      string    `json:"sec_uid"`
+	NickName    string    `json:"nick_name"`
+	FollowCount uint64    `json:"follow_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ShareEvent 分享事件
+type ShareEvent struct {
+	SessionId   int       `json:"session_id" gorm:"column:live_id"`
+	MsgId       uint64    `json:"msg_id"`
+	UserId      uint64    `json:"user_id"`
+	SecUid      string    `json:"sec_uid"`
+	NickName    string    `json:"nick_name"`
+	ShareType   uint64    `json:"share_type"`
+	ShareTarget string    `json:"share_target"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SessionSocial 单场直播的关注与分享统计
+type SessionSocial struct {
+	SessionId      int    `json:"session_id" gorm:"column:live_id"`
+	Follows        int    `json:"follows"`
+	FollowerGain   int64  `json:"follower_gain"`
+	Shares         int    `json:"shares"`
+	SharesByTarget string `json:"shares_by_target"`
+}
+
+func InsertFollowEvent(event *FollowEvent) error {
+	if !database.Enabled() {
+		return nil
+	}
+	return database.DB.Table("follow_events").Create(event).Error
+}
+
+func InsertShareEvent(event *ShareEvent) error {
+	if !database.Enabled() {
+		return nil
+	}
+	return database.DB.Table("share_events").Create(event).Error
+}
+
+func InsertSessionSocial(social *SessionSocial) error {
+	if !database.Enabled() {
+		return nil
+	}
+	return database.DB.Table("session_socials").Create(social).Error
+}
@@ -0,0 +1,368 @@
+// Package rawproto 在没有 .proto 定义的情况下解析 protobuf 二进制数据，用于逆向未知消息
+package rawproto
+
+import (
+	"douyinlive/generated"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// MaxDepth 嵌套消息的最大解析深度
+const MaxDepth = 32
+
+// 字段的解析结果类型
+const (
+	KindVarint        = "varint"
+	KindFixed32       = "fixed32"
+	KindFixed64       = "fixed64"
+	KindString        = "string"
+	KindBytes         = "bytes"
+	KindMessage       = "message"
+	KindPacked        = "packed" // packed varint
+	KindPackedFixed32 = "packed_fixed32"
+	KindPackedFixed64 = "packed_fixed64"
+)
+
+// Field 一个字段的解析结果
+type Field struct {
+	Number int32       `json:"field"`
+	Kind   string      `json:"kind"`
+	Value  interface{} `json:"value,omitempty"`
+	Fields []*Field    `json:"fields,omitempty"`
+	// Type 与 Known 在嵌套内容被识别为已知消息时填充
+	Type  string          `json:"type,omitempty"`
+	Known json.RawMessage `json:"known,omitempty"`
+
+	raw []byte // length-delimited 字段的原始数据
+}
+
+// Varint varint 字段的多种解释
+type Varint struct {
+	Uint uint64 `json:"uint"`
+	Sint int64  `json:"sint"`
+}
+
+// Fixed32 fixed32 字段的多种解释
+type Fixed32 struct {
+	Uint  uint32  `json:"uint"`
+	Float float32 `json:"float"`
+}
+
+// Fixed64 fixed64 字段的多种解释
+type Fixed64 struct {
+	Uint   uint64  `json:"uint"`
+	Double float64 `json:"double"`
+}
+
+// Decode 解析任意 protobuf 数据为字段树
+func Decode(data []byte) ([]*Field, error) {
+	return decode(data, 0)
+}
+
+// DecodeJSON 解析任意 protobuf 数据并输出 JSON
+func DecodeJSON(data []byte) ([]byte, error) {
+	fields, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(fields, "", "  ")
+}
+
+func decode(data []byte, depth int) ([]*Field, error) {
+	if depth > MaxDepth {
+		return nil, errors.New("嵌套层级过深")
+	}
+	fields := make([]*Field, 0)
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		if num > protowire.MaxValidNumber {
+			return nil, errors.New("字段编号无效")
+		}
+		data = data[n:]
+		field := &Field{Number: int32(num)}
+		switch typ {
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			data = data[n:]
+			field.Kind = KindVarint
+			field.Value = Varint{Uint: v, Sint: protowire.DecodeZigZag(v)}
+		case protowire.Fixed32Type:
+			v, n := protowire.ConsumeFixed32(data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			data = data[n:]
+			field.Kind = KindFixed32
+			field.Value = Fixed32{Uint: v, Float: finite32(math.Float32frombits(v))}
+		case protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			data = data[n:]
+			field.Kind = KindFixed64
+			field.Value = Fixed64{Uint: v, Double: finite64(math.Float64frombits(v))}
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			data = data[n:]
+			field.raw = v
+			decodeBytes(field, v, depth)
+		default:
+			return nil, errors.New("不支持的 wire type")
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// decodeBytes 按 字符串 -> 嵌套消息 -> packed 浮点数 -> packed varint -> packed fixed -> 原始字节
+// 的顺序猜测 length-delimited 字段的类型
+//
+// 几乎任何数据都能解析为 packed varint，因此长度为 8 或 4 的倍数且全部是常见范围内的浮点数时
+// 优先按 packed double/float 解析，varint 解析失败时再按长度退回 packed fixed64/fixed32
+func decodeBytes(field *Field, v []byte, depth int) {
+	if len(v) == 0 {
+		field.Kind = KindString
+		field.Value = ""
+		return
+	}
+	if isPrintable(v) {
+		field.Kind = KindString
+		field.Value = string(v)
+		return
+	}
+	if nested, err := decode(v, depth+1); err == nil {
+		field.Kind = KindMessage
+		field.Fields = nested
+		recognize(field)
+		return
+	}
+	doubles, isDoubles := decodePackedFixed64(v)
+	isDoubles = isDoubles && plausibleDoubles(doubles)
+	floats, isFloats := decodePackedFixed32(v)
+	isFloats = isFloats && plausibleFloats(floats)
+	if isDoubles && isFloats {
+		// 两种解释都合理时取尾数有效位更少的一种，例如 [1.5, 2.5] 的 float 数组按 double 解析会得到很长的尾数
+		isDoubles = doubleBits(doubles) <= floatBits(floats)
+		isFloats = !isDoubles
+	}
+	if isDoubles {
+		field.Kind = KindPackedFixed64
+		field.Value = doubles
+		return
+	}
+	if isFloats {
+		field.Kind = KindPackedFixed32
+		field.Value = floats
+		return
+	}
+	if packed, ok := decodePacked(v); ok {
+		field.Kind = KindPacked
+		field.Value = packed
+		return
+	}
+	if packed, ok := decodePackedFixed64(v); ok {
+		field.Kind = KindPackedFixed64
+		field.Value = packed
+		return
+	}
+	if packed, ok := decodePackedFixed32(v); ok {
+		field.Kind = KindPackedFixed32
+		field.Value = packed
+		return
+	}
+	field.Kind = KindBytes
+	field.Value = hex.EncodeToString(v)
+}
+
+// recognize 识别 douyin.Message 结构（field 1 为已知方法名、field 2 为 payload），并按已知类型解码 payload
+func recognize(field *Field) {
+	var method string
+	var payload *Field
+	for _, f := range field.Fields {
+		switch {
+		case f.Number == 1 && f.Kind == KindString:
+			method, _ = f.Value.(string)
+		case f.Number == 2 && f.raw != nil:
+			payload = f
+		}
+	}
+	createMessage, ok := generated.MessageMap[method]
+	if !ok || payload == nil {
+		return
+	}
+	msg := createMessage()
+	if err := proto.Unmarshal(payload.raw, msg); err != nil {
+		return
+	}
+	known, err := protojson.Marshal(msg)
+	if err != nil {
+		return
+	}
+	payload.Type = string(msg.ProtoReflect().Descriptor().FullName())
+	payload.Known = known
+}
+
+// decodePacked 尝试将数据解析为 packed varint 数组
+func decodePacked(v []byte) ([]uint64, bool) {
+	var values []uint64
+	for len(v) > 0 {
+		x, n := protowire.ConsumeVarint(v)
+		if n < 0 {
+			return nil, false
+		}
+		values = append(values, x)
+		v = v[n:]
+	}
+	return values, true
+}
+
+// decodePackedFixed32 长度为 4 的倍数时将数据解析为 packed fixed32 数组
+func decodePackedFixed32(v []byte) ([]Fixed32, bool) {
+	if len(v)%4 != 0 {
+		return nil, false
+	}
+	values := make([]Fixed32, 0, len(v)/4)
+	for ; len(v) > 0; v = v[4:] {
+		x, _ := protowire.ConsumeFixed32(v)
+		values = append(values, Fixed32{Uint: x, Float: finite32(math.Float32frombits(x))})
+	}
+	return values, true
+}
+
+// decodePackedFixed64 长度为 8 的倍数时将数据解析为 packed fixed64 数组
+func decodePackedFixed64(v []byte) ([]Fixed64, bool) {
+	if len(v)%8 != 0 {
+		return nil, false
+	}
+	values := make([]Fixed64, 0, len(v)/8)
+	for ; len(v) > 0; v = v[8:] {
+		x, _ := protowire.ConsumeFixed64(v)
+		values = append(values, Fixed64{Uint: x, Double: finite64(math.Float64frombits(x))})
+	}
+	return values, true
+}
+
+// plausibleFloat 是否为常见范围内的浮点数，用于区分 packed 浮点数与其他数据
+func plausibleFloat(f float64) bool {
+	if f == 0 {
+		return true
+	}
+	f = math.Abs(f)
+	return f >= 1e-6 && f <= 1e12
+}
+
+// plausibleFloats 每个值都是 0 或常见范围内的 float，且不全为 0
+func plausibleFloats(values []Fixed32) bool {
+	nonzero := false
+	for _, v := range values {
+		if v.Uint != 0 && (v.Float == 0 || !plausibleFloat(float64(v.Float))) {
+			return false
+		}
+		nonzero = nonzero || v.Uint != 0
+	}
+	return nonzero
+}
+
+// plausibleDoubles 每个值都是 0 或常见范围内的 double，且不全为 0
+func plausibleDoubles(values []Fixed64) bool {
+	nonzero := false
+	for _, v := range values {
+		if v.Uint != 0 && (v.Double == 0 || !plausibleFloat(v.Double)) {
+			return false
+		}
+		nonzero = nonzero || v.Uint != 0
+	}
+	return nonzero
+}
+
+// floatBits 各 float 尾数的有效位数之和
+func floatBits(values []Fixed32) int {
+	n := 0
+	for _, v := range values {
+		if mantissa := v.Uint & (1<<23 - 1); mantissa != 0 {
+			n += 23 - bits.TrailingZeros32(mantissa)
+		}
+	}
+	return n
+}
+
+// doubleBits 各 double 尾数的有效位数之和
+func doubleBits(values []Fixed64) int {
+	n := 0
+	for _, v := range values {
+		if mantissa := v.Uint & (1<<52 - 1); mantissa != 0 {
+			n += 52 - bits.TrailingZeros64(mantissa)
+		}
+	}
+	return n
+}
+
+// isPrintable 判断数据是否为可打印的 UTF-8 文本
+func isPrintable(v []byte) bool {
+	if !utf8.Valid(v) {
+		return false
+	}
+	for _, r := range string(v) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// finite32 JSON 无法表示 NaN/Inf，此类值置零
+func finite32(f float32) float32 {
+	if math.IsNaN(float64(f)) || math.IsInf(float64(f), 0) {
+		return 0
+	}
+	return f
+}
+
+func finite64(f float64) float64 {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0
+	}
+	return f
+}
+
+// Shape 返回字段树的结构签名，忽略字段值与连续重复次数，结构相同的消息签名一致
+func Shape(fields []*Field) string {
+	var b strings.Builder
+	var last string
+	for _, field := range fields {
+		part := strconv.Itoa(int(field.Number)) + ":" + field.Kind
+		if field.Kind == KindMessage {
+			part += "{" + Shape(field.Fields) + "}"
+		}
+		if part == last {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(part)
+		last = part
+	}
+	return b.String()
+}
@@ -0,0 +1,108 @@
+package rawproto
+
+import (
+	"douyinlive/generated/douyin"
+	"math"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDecodeResponse(t *testing.T) {
+	chat, _ := proto.Marshal(&douyin.ChatMessage{
+		User:    &douyin.User{Id: 42, NickName: "观众"},
+		Content: "你好",
+	})
+	data, _ := proto.Marshal(&douyin.Response{
+		MessagesList: []*douyin.Message{
+			{Method: "WebcastChatMessage", Payload: chat, MsgId: -1},
+			{Method: "WebcastNewMessage", Payload: []byte{0x08, 0x96, 0x01, 0x12, 0x02, 0xff, 0xfe}},
+		},
+		Cursor:  "t-1",
+		NeedAck: true,
+	})
+
+	fields, err := Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 4 {
+		t.Fatalf("字段数量 = %d", len(fields))
+	}
+
+	known := fields[0]
+	if known.Kind != KindMessage || known.Fields[1].Type != "ChatMessage" || len(known.Fields[1].Known) == 0 {
+		t.Fatalf("未识别已知消息: %+v", known.Fields[1])
+	}
+	if v := known.Fields[2].Value.(Varint); v.Uint != 1<<64-1 {
+		t.Fatalf("varint 解析错误: %+v", v)
+	}
+
+	unknown := fields[1].Fields[1]
+	if unknown.Kind != KindMessage || unknown.Known != nil {
+		t.Fatalf("未知消息解析错误: %+v", unknown)
+	}
+	if v := unknown.Fields[0].Value.(Varint); v.Uint != 150 {
+		t.Fatalf("varint 解析错误: %+v", v)
+	}
+	if unknown.Fields[1].Kind != KindBytes {
+		t.Fatalf("字节解析错误: %+v", unknown.Fields[1])
+	}
+	if fields[2].Kind != KindString || fields[2].Value != "t-1" {
+		t.Fatalf("字符串解析错误: %+v", fields[2])
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	if _, err := Decode([]byte{0x0a, 0x05, 0x01}); err == nil {
+		t.Fatal("截断的数据应当返回错误")
+	}
+}
+
+// packedField 以 length-delimited 字段 1 包装 packed 数据后解析
+func packedField(t *testing.T, packed []byte) *Field {
+	t.Helper()
+	fields, err := Decode(protowire.AppendBytes(protowire.AppendTag(nil, 1, protowire.BytesType), packed))
+	if err != nil || len(fields) != 1 {
+		t.Fatalf("解析失败: %v %+v", err, fields)
+	}
+	return fields[0]
+}
+
+func TestDecodePacked(t *testing.T) {
+	var doubles, floats, ids, flags, varints []byte
+	for _, f := range []float64{1.5, -2.25, 1e6} {
+		doubles = protowire.AppendFixed64(doubles, math.Float64bits(f))
+	}
+	for _, f := range []float32{1.5, 2.5} {
+		floats = protowire.AppendFixed32(floats, math.Float32bits(f))
+	}
+	for _, id := range []uint64{0xff00000000000001, 0xfe00000000000002} {
+		ids = protowire.AppendFixed64(ids, id)
+	}
+	for i := 0; i < 3; i++ {
+		flags = protowire.AppendFixed32(flags, 0xff000001)
+	}
+	for _, v := range []uint64{1, 300, 2} {
+		varints = protowire.AppendVarint(varints, v)
+	}
+
+	if f := packedField(t, doubles); f.Kind != KindPackedFixed64 || f.Value.([]Fixed64)[1].Double != -2.25 {
+		t.Fatalf("packed double 解析错误: %+v", f)
+	}
+	// 8 字节的 float 数组同样可以解释为一个 double，按尾数有效位区分
+	if f := packedField(t, floats); f.Kind != KindPackedFixed32 || f.Value.([]Fixed32)[1].Float != 2.5 {
+		t.Fatalf("packed float 解析错误: %+v", f)
+	}
+	// 不是常见范围内的浮点数且无法解析为 varint 时按长度退回 fixed64/fixed32
+	if f := packedField(t, ids); f.Kind != KindPackedFixed64 || f.Value.([]Fixed64)[0].Uint != 0xff00000000000001 {
+		t.Fatalf("packed fixed64 解析错误: %+v", f)
+	}
+	if f := packedField(t, flags); f.Kind != KindPackedFixed32 || len(f.Value.([]Fixed32)) != 3 {
+		t.Fatalf("packed fixed32 解析错误: %+v", f)
+	}
+	if f := packedField(t, varints); f.Kind != KindPacked || f.Value.([]uint64)[1] != 300 {
+		t.Fatalf("packed varint 解析错误: %+v", f)
+	}
+}
@@ -0,0 +1,64 @@
+package douyinlive
+
+import (
+	"douyinlive/archive"
+	"errors"
+	"log"
+	"time"
+)
+
+// StartRecording 开始将收到的原始 PushFrame（解压前）写入归档文件
+func (d *DouyinLive) StartRecording(path string) error {
+	d.recordMu.Lock()
+	defer d.recordMu.Unlock()
+	if d.recorder != nil {
+		return errors.New("直播间已在录制中")
+	}
+	w, err := archive.Create(path, archive.Header{
+		RoomId: string(d.roomID),
+		WebRid: string(d.webRid),
+		Device: archive.DeviceProfile{
+			UserAgent: d.userAgent,
+			DeviceId:  d.pushid,
+		},
+		StartTime: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	d.recorder = w
+	return nil
+}
+
+// StopRecording 停止录制并关闭归档文件，未在录制时直接返回
+func (d *DouyinLive) StopRecording() error {
+	d.recordMu.Lock()
+	defer d.recordMu.Unlock()
+	if d.recorder == nil {
+		return nil
+	}
+	err := d.recorder.Close()
+	d.recorder = nil
+	return err
+}
+
+// Recording 是否正在录制
+func (d *DouyinLive) Recording() bool {
+	d.recordMu.Lock()
+	defer d.recordMu.Unlock()
+	return d.recorder != nil
+}
+
+// recordFrame 录制一帧，写入失败时停止录制
+func (d *DouyinLive) recordFrame(data []byte) {
+	d.recordMu.Lock()
+	defer d.recordMu.Unlock()
+	if d.recorder == nil {
+		return
+	}
+	if err := d.recorder.WriteFrame(time.Now(), data); err != nil {
+		log.Printf("录制帧失败，停止录制: %v\n", err)
+		d.recorder.Close()
+		d.recorder = nil
+	}
+}
@@ -0,0 +1,296 @@
+// Package resolver 将分享短链、直播间链接、主页链接或数字 ID 统一解析为直播间标识
+package resolver
+
+import (
+	"douyinlive/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/imroc/req/v3"
+)
+
+// maxRedirects 跟随短链跳转的最大次数
+const maxRedirects = 10
+
+// roomIdMinLen 直播场次 ID 为 19 位数字，web_rid 通常不超过 12 位，据此区分纯数字输入
+const roomIdMinLen = 16
+
+var (
+	urlRegexp     = regexp.MustCompile(`https?://[^\s"'<>]+`)
+	liveURLRegexp = regexp.MustCompile(`live\.douyin\.com/(\d+)`)
+	reflowRegexp  = regexp.MustCompile(`/reflow/(\d+)`)
+	userURLRegexp = regexp.MustCompile(`douyin\.com/user/([\w-]+)`)
+)
+
+// StatusLive 房间信息中表示正在直播的状态
+const StatusLive = 2
+
+// ErrNotLiving 只知道主播 sec_uid，但主播当前未开播，无法得到直播间
+var ErrNotLiving = errors.New("主播当前未开播")
+
+// Room 解析后的直播间标识，未能获取的字段为空
+type Room struct {
+	WebRid string `json:"web_rid"` // 直播间链接中的 ID，如 live.douyin.com/644826113301
+	RoomId string `json:"room_id"` // 本场直播的内部 ID
+	SecUid string `json:"sec_uid"` // 主播 sec_uid
+}
+
+// Resolver 通过抖音网页接口补全直播间标识
+type Resolver struct {
+	c *req.Client
+	// 以下接口地址可在测试中替换
+	enterURL  string
+	reflowURL string
+	userURL   string
+}
+
+// New 创建解析器
+func New() *Resolver {
+	return &Resolver{
+		c:         req.C().SetUserAgent(utils.RandomUserAgent()).SetRedirectPolicy(req.NoRedirectPolicy()),
+		enterURL:  "https://live.douyin.com/webcast/room/web/enter/",
+		reflowURL: "https://webcast.amemv.com/webcast/room/reflow/info/",
+		userURL:   "https://www.iesdouyin.com/web/api/v2/user/info/",
+	}
+}
+
+// Resolve 使用默认解析器解析
+func Resolve(input string) (*Room, error) {
+	return New().Resolve(input)
+}
+
+// Parse 不发起请求，仅从输入中提取能直接识别的字段，无法识别时返回 false
+//
+// 支持 web_rid、19 位房间 ID、live.douyin.com/<web_rid>、带 sec_uid 的主页链接、reflow 分享页链接
+// 以及包含上述链接的分享文案
+func Parse(input string) (*Room, bool) {
+	input = strings.TrimSpace(input)
+	if _, err := strconv.ParseUint(input, 10, 64); err == nil {
+		if len(input) >= roomIdMinLen {
+			return &Room{RoomId: input}, true
+		}
+		return &Room{WebRid: input}, true
+	}
+	if link := urlRegexp.FindString(input); link != "" {
+		input = link
+	}
+
+	room := &Room{}
+	if match := liveURLRegexp.FindStringSubmatch(input); match != nil {
+		room.WebRid = match[1]
+	}
+	if match := reflowRegexp.FindStringSubmatch(input); match != nil {
+		room.RoomId = match[1]
+	}
+	if match := userURLRegexp.FindStringSubmatch(input); match != nil {
+		room.SecUid = match[1]
+	}
+	if u, err := url.Parse(input); err == nil {
+		query := u.Query()
+		if room.WebRid == "" {
+			room.WebRid = query.Get("web_rid")
+		}
+		if room.RoomId == "" {
+			room.RoomId = query.Get("room_id")
+		}
+		if room.SecUid == "" {
+			room.SecUid = firstNonEmpty(query.Get("sec_uid"), query.Get("sec_user_id"))
+		}
+	}
+	return room, *room != Room{}
+}
+
+// Resolve 解析任意形式的输入，短链会跟随跳转，并尽量补全 web_rid、room_id 与 sec_uid
+//
+// 只有无法得到 web_rid 时返回错误，其余字段补全失败时保持为空
+func (r *Resolver) Resolve(input string) (*Room, error) {
+	room, ok := Parse(input)
+	if !ok {
+		link := urlRegexp.FindString(input)
+		if link == "" {
+			return nil, fmt.Errorf("无法识别的直播间: %s", strings.TrimSpace(input))
+		}
+		var err error
+		if room, err = r.follow(link); err != nil {
+			return nil, err
+		}
+	}
+
+	if room.WebRid == "" && room.RoomId == "" {
+		roomId, err := r.userRoomId(room.SecUid)
+		if err != nil {
+			return nil, err
+		}
+		room.RoomId = roomId
+	}
+	if room.WebRid == "" {
+		if err := r.reflow(room); err != nil {
+			return nil, err
+		}
+	}
+	if room.WebRid == "" {
+		return nil, fmt.Errorf("无法获取直播间 %s 的 web_rid", room.RoomId)
+	}
+	if room.RoomId == "" || room.SecUid == "" {
+		_, _ = r.enter(room)
+	}
+	return room, nil
+}
+
+// Live 查询主播是否正在直播，room 需要包含 web_rid 或 sec_uid，正在直播时返回补全后的直播间标识
+func (r *Resolver) Live(room Room) (*Room, bool, error) {
+	room.RoomId = ""
+	if room.WebRid != "" {
+		status, err := r.enter(&room)
+		if err != nil {
+			return nil, false, fmt.Errorf("查询直播间 %s 失败: %w", room.WebRid, err)
+		}
+		return &room, status == StatusLive, nil
+	}
+	if room.SecUid == "" {
+		return nil, false, errors.New("缺少 web_rid 或 sec_uid")
+	}
+	roomId, err := r.userRoomId(room.SecUid)
+	if errors.Is(err, ErrNotLiving) {
+		return &room, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	room.RoomId = roomId
+	if err := r.reflow(&room); err != nil {
+		return nil, false, err
+	}
+	return &room, room.WebRid != "", nil
+}
+
+// follow 逐跳跟随跳转，直到链接中能识别出直播间或主播
+func (r *Resolver) follow(link string) (*Room, error) {
+	for i := 0; i < maxRedirects; i++ {
+		resp, err := r.c.R().Get(link)
+		if err != nil {
+			return nil, fmt.Errorf("请求 %s 失败: %w", link, err)
+		}
+		location := resp.Header.Get("Location")
+		if location == "" {
+			break
+		}
+		base, err := url.Parse(link)
+		if err != nil {
+			return nil, err
+		}
+		next, err := base.Parse(location)
+		if err != nil {
+			return nil, fmt.Errorf("跳转地址错误: %w", err)
+		}
+		link = next.String()
+		if room, ok := Parse(link); ok {
+			return room, nil
+		}
+	}
+	return nil, fmt.Errorf("无法从链接中识别直播间: %s", link)
+}
+
+// enter 通过 web_rid 查询当前场次的 room_id、主播 sec_uid 与房间状态
+func (r *Resolver) enter(room *Room) (int, error) {
+	var result struct {
+		Data struct {
+			Data []struct {
+				IdStr  string `json:"id_str"`
+				Status int    `json:"status"`
+			} `json:"data"`
+			User struct {
+				SecUid string `json:"sec_uid"`
+			} `json:"user"`
+		} `json:"data"`
+	}
+	err := r.getJSON(r.enterURL, map[string]string{
+		"aid":              "6383",
+		"app_name":         "douyin_web",
+		"live_id":          "1",
+		"device_platform":  "web",
+		"language":         "zh-CN",
+		"browser_language": "zh-CN",
+		"web_rid":          room.WebRid,
+	}, &result)
+	if err != nil {
+		return 0, err
+	}
+	room.SecUid = firstNonEmpty(room.SecUid, result.Data.User.SecUid)
+	if len(result.Data.Data) == 0 {
+		return 0, nil
+	}
+	if room.RoomId == "" {
+		room.RoomId = result.Data.Data[0].IdStr
+	}
+	return result.Data.Data[0].Status, nil
+}
+
+// reflow 通过 room_id 查询直播间的 web_rid 与主播 sec_uid
+func (r *Resolver) reflow(room *Room) error {
+	var result struct {
+		Data struct {
+			Room struct {
+				Owner struct {
+					WebRid string `json:"web_rid"`
+					SecUid string `json:"sec_uid"`
+				} `json:"owner"`
+			} `json:"room"`
+		} `json:"data"`
+	}
+	err := r.getJSON(r.reflowURL, map[string]string{
+		"type_id":     "0",
+		"live_id":     "1",
+		"app_id":      "1128",
+		"room_id":     room.RoomId,
+		"sec_user_id": room.SecUid,
+	}, &result)
+	if err != nil {
+		return fmt.Errorf("查询直播间 %s 失败: %w", room.RoomId, err)
+	}
+	room.WebRid = result.Data.Room.Owner.WebRid
+	room.SecUid = firstNonEmpty(room.SecUid, result.Data.Room.Owner.SecUid)
+	return nil
+}
+
+// userRoomId 通过主播 sec_uid 查询当前直播的 room_id
+func (r *Resolver) userRoomId(secUid string) (string, error) {
+	var result struct {
+		UserInfo struct {
+			RoomId int64 `json:"room_id"`
+		} `json:"user_info"`
+	}
+	if err := r.getJSON(r.userURL, map[string]string{"sec_uid": secUid}, &result); err != nil {
+		return "", fmt.Errorf("查询主播 %s 失败: %w", secUid, err)
+	}
+	if result.UserInfo.RoomId == 0 {
+		return "", ErrNotLiving
+	}
+	return strconv.FormatInt(result.UserInfo.RoomId, 10), nil
+}
+
+// getJSON 发起 GET 请求并解析 JSON 响应
+func (r *Resolver) getJSON(api string, params map[string]string, v interface{}) error {
+	resp, err := r.c.R().SetQueryParams(params).Get(api)
+	if err != nil {
+		return err
+	}
+	if !resp.IsSuccessState() {
+		return fmt.Errorf("状态码 %d", resp.StatusCode)
+	}
+	return json.Unmarshal(resp.Bytes(), v)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
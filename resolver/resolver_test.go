@@ -0,0 +1,96 @@
+package resolver
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := map[string]Room{
+		"644826113301":        {WebRid: "644826113301"},
+		"7383731312643626035": {RoomId: "7383731312643626035"},
+		"https://live.douyin.com/644826113301?enter_from_merge=web_live":  {WebRid: "644826113301"},
+		"https://www.douyin.com/user/MS4wLjABAAAA-x_y?from_tab_name=main": {SecUid: "MS4wLjABAAAA-x_y"},
+		"https://webcast.amemv.com/douyin/webcast/reflow/7383731312643626035?sec_user_id=MS4wLjABAAAA": {
+			RoomId: "7383731312643626035", SecUid: "MS4wLjABAAAA",
+		},
+		"8- 长按复制此条消息，打开抖音搜索，查看TA的更多作品。 https://live.douyin.com/644826113301 ": {WebRid: "644826113301"},
+	}
+	for input, want := range cases {
+		got, ok := Parse(input)
+		if !ok || *got != want {
+			t.Fatalf("Parse(%q) = %+v, %v", input, got, ok)
+		}
+	}
+	if _, ok := Parse("https://v.douyin.com/iMDdJd9s/"); ok {
+		t.Fatal("短链不应直接识别")
+	}
+}
+
+func TestResolveShortLink(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/iMDdJd9s/", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/share/live", http.StatusFound)
+	})
+	mux.HandleFunc("/share/live", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://webcast.amemv.com/douyin/webcast/reflow/7383731312643626035", http.StatusFound)
+	})
+	mux.HandleFunc("/reflow", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("room_id") != "7383731312643626035" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"room":{"owner":{"web_rid":"23020419981","sec_uid":"MS4wLjABAAAA"}}}}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	r := New()
+	r.reflowURL = server.URL + "/reflow"
+	r.enterURL = server.URL + "/enter"
+	room, err := r.Resolve("看直播 " + server.URL + "/iMDdJd9s/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Room{WebRid: "23020419981", RoomId: "7383731312643626035", SecUid: "MS4wLjABAAAA"}
+	if *room != want {
+		t.Fatalf("解析结果错误: %+v", room)
+	}
+}
+
+func TestResolveNotLiving(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"user_info":{"room_id":0}}`)
+	}))
+	defer server.Close()
+
+	r := New()
+	r.userURL = server.URL
+	if _, err := r.Resolve("https://www.douyin.com/user/MS4wLjABAAAA"); err != ErrNotLiving {
+		t.Fatalf("期望 ErrNotLiving，得到 %v", err)
+	}
+}
+
+func TestLive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("web_rid") {
+		case "1":
+			fmt.Fprint(w, `{"data":{"data":[{"id_str":"7383731312643626035","status":2}],"user":{"sec_uid":"MS4wLjABAAAA"}}}`)
+		default:
+			fmt.Fprint(w, `{"data":{"data":[{"id_str":"7383731312643626000","status":4}],"user":{"sec_uid":"MS4wLjABAAAB"}}}`)
+		}
+	}))
+	defer server.Close()
+
+	r := New()
+	r.enterURL = server.URL
+	room, live, err := r.Live(Room{WebRid: "1", RoomId: "7000000000000000000"})
+	if err != nil || !live || room.RoomId != "7383731312643626035" || room.SecUid != "MS4wLjABAAAA" {
+		t.Fatalf("直播中解析错误: %+v %v %v", room, live, err)
+	}
+	if _, live, err := r.Live(Room{WebRid: "2"}); err != nil || live {
+		t.Fatalf("未开播解析错误: %v %v", live, err)
+	}
+}
@@ -0,0 +1,126 @@
+package richtext
+
+import (
+	"douyinlive/generated/douyin"
+	"douyinlive/utils"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// SocialMessage 的 action 取值，与 douyinlive.SocialActionFollow、SocialActionShare 一致
+const (
+	socialActionFollow = 1
+	socialActionShare  = 3
+)
+
+// liveStatusEnded ControlMessage 中表示直播结束的状态
+const liveStatusEnded = 3
+
+// commonMessage 带有 Common 字段的消息
+type commonMessage interface {
+	GetCommon() *douyin.Common
+}
+
+// nickName 用户昵称，缺失时返回"匿名用户"
+func nickName(user *douyin.User) string {
+	if user == nil || user.NickName == "" {
+		return "匿名用户"
+	}
+	return user.NickName
+}
+
+// Describe 生成消息的单行可读描述，用于日志和直播间浮层
+func Describe(msg proto.Message) string {
+	switch m := msg.(type) {
+	case *douyin.ChatMessage:
+		if m.RtfContent != nil {
+			return nickName(m.User) + ": " + oneLine(Plain(m.RtfContent))
+		}
+		return nickName(m.User) + ": " + oneLine(m.Content)
+	case *douyin.EmojiChatMessage:
+		content := m.DefaultContent
+		if m.EmojiContent != nil {
+			content = Plain(m.EmojiContent)
+		}
+		return nickName(m.User) + ": " + oneLine(content)
+	case *douyin.GiftMessage:
+		if text := Plain(m.TrayDisplayText); text != "" {
+			return oneLine(text)
+		}
+		name := m.GetGift().GetName()
+		if name == "" {
+			name = fmt.Sprintf("礼物%d", m.GiftId)
+		}
+		count := m.RepeatCount
+		if m.ComboCount != "" && m.ComboCount != "0" {
+			count = m.ComboCount
+		}
+		return fmt.Sprintf("%s 送出 %s x%s", nickName(m.User), name, count)
+	case *douyin.LikeMessage:
+		return fmt.Sprintf("%s 点赞 x%d（总点赞 %d）", nickName(m.User), m.Count, m.Total)
+	case *douyin.MemberMessage:
+		if text := Plain(m.GetCommon().GetDisplayText()); text != "" {
+			return oneLine(text)
+		}
+		return fmt.Sprintf("%s 进入直播间（在线 %d）", nickName(m.User), m.MemberCount)
+	case *douyin.SocialMessage:
+		switch m.Action {
+		case socialActionFollow:
+			return fmt.Sprintf("%s 关注了主播（粉丝 %d）", nickName(m.User), m.FollowCount)
+		case socialActionShare:
+			if m.ShareTarget != "" {
+				return fmt.Sprintf("%s 分享了直播间到 %s", nickName(m.User), m.ShareTarget)
+			}
+			return nickName(m.User) + " 分享了直播间"
+		}
+	case *douyin.RoomUserSeqMessage:
+		return fmt.Sprintf("在线观众 %d，累计观看 %s", m.Total, m.TotalPvForAnchor)
+	case *douyin.RoomStatsMessage:
+		return "直播间统计: " + m.DisplayLong
+	case *douyin.ControlMessage:
+		if m.Status == liveStatusEnded {
+			return "直播已结束"
+		}
+		return fmt.Sprintf("直播间状态变更: %d", m.Status)
+	case *douyin.FansclubMessage:
+		return nickName(m.User) + " " + m.Content
+	case *douyin.RoomMessage:
+		return oneLine(m.Content)
+	case *douyin.LiveShoppingMessage:
+		return fmt.Sprintf("购物车消息: 商品 %d（类型 %d）", m.PromotionId, m.MsgType)
+	case *douyin.ProductChangeMessage:
+		if m.UpdateToast != "" {
+			return "商品更新: " + oneLine(m.UpdateToast)
+		}
+		return fmt.Sprintf("商品更新: 共 %d 件商品", m.Total)
+	}
+
+	if cm, ok := msg.(commonMessage); ok {
+		if text := Plain(cm.GetCommon().GetDisplayText()); text != "" {
+			return oneLine(text)
+		}
+		if describe := cm.GetCommon().GetDescribe(); describe != "" {
+			return oneLine(describe)
+		}
+	}
+	return string(msg.ProtoReflect().Descriptor().Name())
+}
+
+// DescribeMessage 解码 Message 的 payload 并生成单行描述，未知消息返回方法名
+func DescribeMessage(message *douyin.Message) string {
+	msg, err := utils.MatchMethod(message.Method)
+	if err != nil {
+		return message.Method
+	}
+	if err := proto.Unmarshal(message.Payload, msg); err != nil {
+		return message.Method
+	}
+	return Describe(msg)
+}
+
+// oneLine 去除换行，保证描述为单行
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
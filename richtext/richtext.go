@@ -0,0 +1,178 @@
+package richtext
+
+import (
+	"douyinlive/generated/douyin"
+	"html"
+	"strconv"
+	"strings"
+)
+
+// Token 类型
+const (
+	TokenText    = "text"
+	TokenUser    = "user"
+	TokenGift    = "gift"
+	TokenHeart   = "heart"
+	TokenImage   = "image"
+	TokenPattern = "pattern"
+)
+
+// Token 富文本渲染后的一个片段
+type Token struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	Color    string `json:"color,omitempty"`
+	Bold     bool   `json:"bold,omitempty"`
+	Italic   bool   `json:"italic,omitempty"`
+	UserId   uint64 `json:"user_id,omitempty"`
+	GiftId   uint64 `json:"gift_id,omitempty"`
+	ImageURL string `json:"image_url,omitempty"`
+}
+
+// Tokens 将 Text 的 default_pattern 与 pieces 合成为片段列表
+//
+// default_pattern 中的占位符形如 {0:user}、{1}，数字为 pieces 的下标，
+// 没有 pattern 时按顺序拼接所有 pieces。
+func Tokens(t *douyin.Text) []Token {
+	if t == nil {
+		return nil
+	}
+	pattern := t.DefaultPattern
+	if pattern == "" {
+		tokens := make([]Token, 0, len(t.Pieces))
+		for _, piece := range t.Pieces {
+			tokens = append(tokens, pieceToken(piece, t.DefaultFormat))
+		}
+		return tokens
+	}
+
+	var tokens []Token
+	var literal strings.Builder
+	flush := func() {
+		if literal.Len() > 0 {
+			tokens = append(tokens, formatToken(Token{Type: TokenText, Text: literal.String()}, t.DefaultFormat))
+			literal.Reset()
+		}
+	}
+	for i := 0; i < len(pattern); {
+		if pattern[i] == '{' {
+			if end := strings.IndexByte(pattern[i:], '}'); end > 0 {
+				placeholder := pattern[i+1 : i+end]
+				if idx, ok := placeholderIndex(placeholder); ok && idx < len(t.Pieces) {
+					flush()
+					tokens = append(tokens, pieceToken(t.Pieces[idx], t.DefaultFormat))
+					i += end + 1
+					continue
+				}
+			}
+		}
+		literal.WriteByte(pattern[i])
+		i++
+	}
+	flush()
+	return tokens
+}
+
+// placeholderIndex 解析占位符中的下标，"0:user" 返回 0
+func placeholderIndex(placeholder string) (int, bool) {
+	if n := strings.IndexByte(placeholder, ':'); n >= 0 {
+		placeholder = placeholder[:n]
+	}
+	idx, err := strconv.Atoi(placeholder)
+	if err != nil || idx < 0 {
+		return 0, false
+	}
+	return idx, true
+}
+
+// pieceToken 将 TextPiece 转换为 Token
+func pieceToken(piece *douyin.TextPiece, def *douyin.TextFormat) Token {
+	var token Token
+	switch {
+	case piece.Uservalue != nil:
+		token = Token{Type: TokenUser}
+		if user := piece.Uservalue.User; user != nil {
+			token.Text = user.NickName
+			token.UserId = user.Id
+		}
+		token.Text = piece.Uservalue.LeftAdditionalContent + token.Text + piece.Uservalue.RightAdditionalContent
+		if piece.Uservalue.WithColon {
+			token.Text += "："
+		}
+	case piece.Giftvalue != nil:
+		token = Token{Type: TokenGift, GiftId: piece.Giftvalue.GiftId}
+		token.Text = piece.Giftvalue.GetNameRef().GetDefaultPattern()
+		if token.Text == "" {
+			token.Text = "礼物" + strconv.FormatUint(piece.Giftvalue.GiftId, 10)
+		}
+	case piece.Heartvalue != nil:
+		token = Token{Type: TokenHeart, Text: "❤", Color: piece.Heartvalue.Color}
+	case piece.Imagevalue != nil:
+		token = Token{Type: TokenImage, Text: "[图片]"}
+		if image := piece.Imagevalue.Image; image != nil {
+			if image.Content != nil && image.Content.AlternativeText != "" {
+				token.Text = "[" + image.Content.AlternativeText + "]"
+			}
+			if len(image.UrlList) > 0 {
+				token.ImageURL = image.UrlList[0]
+			}
+		}
+	case piece.Patternrefvalue != nil:
+		token = Token{Type: TokenPattern, Text: piece.Patternrefvalue.DefaultPattern}
+	default:
+		token = Token{Type: TokenText, Text: piece.StringValue}
+	}
+	if piece.Format != nil {
+		return formatToken(token, piece.Format)
+	}
+	return formatToken(token, def)
+}
+
+// formatToken 应用 TextFormat 中的颜色与字体样式，已有颜色不会被覆盖
+func formatToken(token Token, format *douyin.TextFormat) Token {
+	if format == nil {
+		return token
+	}
+	if token.Color == "" {
+		token.Color = format.Color
+	}
+	token.Bold = token.Bold || format.Bold
+	token.Italic = token.Italic || format.Italic
+	return token
+}
+
+// Plain 渲染为纯文本
+func Plain(t *douyin.Text) string {
+	var b strings.Builder
+	for _, token := range Tokens(t) {
+		b.WriteString(token.Text)
+	}
+	return b.String()
+}
+
+// HTML 渲染为 HTML，颜色与字体样式来自 TextFormat
+func HTML(t *douyin.Text) string {
+	var b strings.Builder
+	for _, token := range Tokens(t) {
+		if token.Type == TokenImage && token.ImageURL != "" {
+			b.WriteString(`<img class="dy-image" src="` + html.EscapeString(token.ImageURL) + `" alt="` + html.EscapeString(token.Text) + `">`)
+			continue
+		}
+		var style []string
+		if token.Color != "" {
+			style = append(style, "color:"+token.Color)
+		}
+		if token.Bold {
+			style = append(style, "font-weight:bold")
+		}
+		if token.Italic {
+			style = append(style, "font-style:italic")
+		}
+		b.WriteString(`<span class="dy-` + token.Type + `"`)
+		if len(style) > 0 {
+			b.WriteString(` style="` + html.EscapeString(strings.Join(style, ";")) + `"`)
+		}
+		b.WriteString(">" + html.EscapeString(token.Text) + "</span>")
+	}
+	return b.String()
+}
@@ -0,0 +1,57 @@
+package richtext
+
+import (
+	"douyinlive/generated/douyin"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	text := &douyin.Text{
+		DefaultPattern: "{0:user} 送出了 {1:gift} {2:string}",
+		DefaultFormat:  &douyin.TextFormat{Color: "#FFFFFF"},
+		Pieces: []*douyin.TextPiece{
+			{Uservalue: &douyin.TextPieceUser{User: &douyin.User{Id: 1, NickName: "<小明>"}}, Format: &douyin.TextFormat{Color: "#FF0000", Bold: true}},
+			{Giftvalue: &douyin.TextPieceGift{GiftId: 463, NameRef: &douyin.PatternRef{DefaultPattern: "小心心"}}},
+			{StringValue: "x3"},
+		},
+	}
+
+	if got := Plain(text); got != "<小明> 送出了 小心心 x3" {
+		t.Fatalf("Plain = %q", got)
+	}
+
+	tokens := Tokens(text)
+	if len(tokens) != 5 || tokens[0].Type != TokenUser || tokens[0].UserId != 1 || tokens[2].GiftId != 463 {
+		t.Fatalf("Tokens = %+v", tokens)
+	}
+	if tokens[1].Color != "#FFFFFF" {
+		t.Fatalf("默认格式未生效: %+v", tokens[1])
+	}
+
+	want := `<span class="dy-user" style="color:#FF0000;font-weight:bold">&lt;小明&gt;</span>` +
+		`<span class="dy-text" style="color:#FFFFFF"> 送出了 </span>`
+	if got := HTML(text); len(got) < len(want) || got[:len(want)] != want {
+		t.Fatalf("HTML = %q", got)
+	}
+}
+
+func TestRenderUnknownPlaceholder(t *testing.T) {
+	text := &douyin.Text{DefaultPattern: "{5:user} 来了 {x}"}
+	if got := Plain(text); got != "{5:user} 来了 {x}" {
+		t.Fatalf("Plain = %q", got)
+	}
+}
+
+func TestDescribeOneLine(t *testing.T) {
+	user := &douyin.User{NickName: "小明"}
+	cases := map[string]string{
+		Describe(&douyin.ChatMessage{User: user, Content: "第一行\n第二行"}):                            "小明: 第一行 第二行",
+		Describe(&douyin.EmojiChatMessage{User: user, DefaultContent: "表情\r\n[笑]"}):               "小明: 表情 [笑]",
+		Describe(&douyin.SocialMessage{User: user, Action: socialActionShare, ShareTarget: "微信"}): "小明 分享了直播间到 微信",
+	}
+	for got, want := range cases {
+		if got != want {
+			t.Errorf("Describe = %q, want %q", got, want)
+		}
+	}
+}
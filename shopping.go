@@ -0,0 +1,239 @@
+package douyinlive
+
+import (
+	"douyinlive/generated/douyin"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 商品时间线事件类型
+const (
+	ProductEventShopping   = "shopping"    // LiveShoppingMessage 推送
+	ProductEventCatalog    = "catalog"     // 商品列表更新
+	ProductEventExplain    = "explain"     // 开始讲解商品
+	ProductEventExplainEnd = "explain_end" // 结束讲解商品
+	ProductEventCategory   = "category"    // 商品分类变化
+)
+
+// ChatCorrelationWindow 统计讲解开始前弹幕量的时间窗口
+const ChatCorrelationWindow = time.Minute
+
+// ProductEvent 商品时间线中的一条记录
+type ProductEvent struct {
+	Kind         string    `json:"kind"`
+	PromotionId  int64     `json:"promotion_id,omitempty"`
+	ExplainType  int64     `json:"explain_type,omitempty"`
+	MsgType      int32     `json:"msg_type,omitempty"`
+	Total        int64     `json:"total,omitempty"`
+	Toast        string    `json:"toast,omitempty"`
+	CategoryId   int32     `json:"category_id,omitempty"`
+	CategoryName string    `json:"category_name,omitempty"`
+	PromotionIds []int64   `json:"promotion_ids,omitempty"`
+	Time         time.Time `json:"time"`
+}
+
+// ProductExplanation 一次商品讲解，以及讲解期间与讲解前的弹幕量
+type ProductExplanation struct {
+	PromotionId int64     `json:"promotion_id"`
+	ExplainType int64     `json:"explain_type"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"` // 讲解尚未结束时为零值
+	Chats       int       `json:"chats"`
+	ChatsBefore int       `json:"chats_before"` // 讲解开始前 ChatCorrelationWindow 内的弹幕量
+}
+
+// ShoppingTracker 根据带货消息生成商品时间线
+type ShoppingTracker struct {
+	mu           sync.Mutex
+	events       []ProductEvent
+	explanations []ProductExplanation
+	current      int         // 正在讲解的商品在 explanations 中的下标，-1 表示无
+	chats        []time.Time // 弹幕时间，按时间升序
+	latest       time.Time   // 最近一条消息的时间，回放时与录制时一致
+}
+
+// NewShoppingTracker 创建商品时间线统计器
+func NewShoppingTracker() *ShoppingTracker {
+	return &ShoppingTracker{current: -1}
+}
+
+// Shopping 处理 LiveShoppingMessage，返回新增的时间线事件
+func (t *ShoppingTracker) Shopping(msg *douyin.LiveShoppingMessage) []ProductEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	event := ProductEvent{
+		Kind:        ProductEventShopping,
+		PromotionId: msg.PromotionId,
+		MsgType:     msg.MsgType,
+		Time:        messageTime(msg.Common),
+	}
+	t.observe(event.Time)
+	t.events = append(t.events, event)
+	return []ProductEvent{event}
+}
+
+// ProductChange 处理 ProductChangeMessage，返回新增的时间线事件
+func (t *ShoppingTracker) ProductChange(msg *douyin.ProductChangeMessage) []ProductEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	at := messageTime(msg.Common)
+	if msg.Common.GetCreateTime() == 0 && msg.UpdateTimestamp > 0 {
+		at = unixTime(msg.UpdateTimestamp)
+	}
+	t.observe(at)
+	events := []ProductEvent{{
+		Kind:  ProductEventCatalog,
+		Total: msg.Total,
+		Toast: msg.UpdateToast,
+		Time:  at,
+	}}
+	for _, product := range msg.UpdateProductInfoList {
+		if product.ExplainType != 0 {
+			events = append(events, t.startExplain(product, at)...)
+		} else if t.explaining(product.PromotionId) {
+			events = append(events, t.endExplain(at))
+		}
+	}
+	for _, category := range msg.UpdateCategoryInfoList {
+		events = append(events, ProductEvent{
+			Kind:         ProductEventCategory,
+			CategoryId:   category.Id,
+			CategoryName: category.Name,
+			PromotionIds: category.PromotionIdsList,
+			Time:         at,
+		})
+	}
+	t.events = append(t.events, events...)
+	return events
+}
+
+// millisThreshold 大于该值的时间戳视为毫秒，按秒计已是三万多年以后
+const millisThreshold = 1e12
+
+// unixTime 将秒或毫秒时间戳转换为时间
+func unixTime(ts int64) time.Time {
+	if ts > millisThreshold {
+		return time.UnixMilli(ts)
+	}
+	return time.Unix(ts, 0)
+}
+
+// observe 记录消息时间，调用方需持有锁
+func (t *ShoppingTracker) observe(at time.Time) {
+	if at.After(t.latest) {
+		t.latest = at
+	}
+}
+
+// now 最近一条消息的时间，尚未收到消息时为当前时间，调用方需持有锁
+func (t *ShoppingTracker) now() time.Time {
+	if t.latest.IsZero() {
+		return time.Now()
+	}
+	return t.latest
+}
+
+// explaining 判断某商品是否正在讲解，调用方需持有锁
+func (t *ShoppingTracker) explaining(promotionId int64) bool {
+	return t.current >= 0 && t.explanations[t.current].PromotionId == promotionId
+}
+
+// startExplain 开始讲解商品，会先结束正在讲解的其他商品，调用方需持有锁
+func (t *ShoppingTracker) startExplain(product *douyin.ProductInfo, at time.Time) []ProductEvent {
+	if t.explaining(product.PromotionId) {
+		return nil
+	}
+	var events []ProductEvent
+	if t.current >= 0 {
+		events = append(events, t.endExplain(at))
+	}
+	t.explanations = append(t.explanations, ProductExplanation{
+		PromotionId: product.PromotionId,
+		ExplainType: product.ExplainType,
+		Start:       at,
+	})
+	t.current = len(t.explanations) - 1
+	return append(events, ProductEvent{
+		Kind:        ProductEventExplain,
+		PromotionId: product.PromotionId,
+		ExplainType: product.ExplainType,
+		Time:        at,
+	})
+}
+
+// endExplain 结束当前讲解，调用方需持有锁
+func (t *ShoppingTracker) endExplain(at time.Time) ProductEvent {
+	explanation := &t.explanations[t.current]
+	explanation.End = at
+	t.current = -1
+	return ProductEvent{
+		Kind:        ProductEventExplainEnd,
+		PromotionId: explanation.PromotionId,
+		ExplainType: explanation.ExplainType,
+		Time:        at,
+	}
+}
+
+// Chat 记录一条弹幕，用于统计讲解期间的弹幕量
+func (t *ShoppingTracker) Chat(at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.observe(at)
+	// 弹幕基本按时间到达，乱序时插入到对应位置
+	i := len(t.chats)
+	if i > 0 && at.Before(t.chats[i-1]) {
+		i = t.searchChats(at)
+	}
+	t.chats = append(t.chats, time.Time{})
+	copy(t.chats[i+1:], t.chats[i:])
+	t.chats[i] = at
+}
+
+// searchChats 第一条不早于 at 的弹幕下标，调用方需持有锁
+func (t *ShoppingTracker) searchChats(at time.Time) int {
+	return sort.Search(len(t.chats), func(i int) bool {
+		return !t.chats[i].Before(at)
+	})
+}
+
+// countChats 统计 [from, to) 区间内的弹幕量，调用方需持有锁
+func (t *ShoppingTracker) countChats(from, to time.Time) int {
+	if !from.Before(to) {
+		return 0
+	}
+	return t.searchChats(to) - t.searchChats(from)
+}
+
+// Timeline 返回商品时间线
+func (t *ShoppingTracker) Timeline() []ProductEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]ProductEvent(nil), t.events...)
+}
+
+// Explanations 返回所有商品讲解及弹幕量，进行中的讲解统计到最近一条消息为止
+func (t *ShoppingTracker) Explanations() []ProductExplanation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	explanations := make([]ProductExplanation, len(t.explanations))
+	for i, e := range t.explanations {
+		end := e.End
+		if end.IsZero() {
+			end = t.now()
+		}
+		e.Chats = t.countChats(e.Start, end)
+		e.ChatsBefore = t.countChats(e.Start.Add(-ChatCorrelationWindow), e.Start)
+		explanations[i] = e
+	}
+	return explanations
+}
+
+// Finish 本场结束，以最近一条消息的时间关闭正在进行的讲解
+func (t *ShoppingTracker) Finish() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.current >= 0 {
+		t.endExplain(t.now())
+	}
+}
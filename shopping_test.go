@@ -0,0 +1,96 @@
+package douyinlive
+
+import (
+	"douyinlive/generated/douyin"
+	"testing"
+	"time"
+)
+
+func productChange(sec int64, products ...*douyin.ProductInfo) *douyin.ProductChangeMessage {
+	return &douyin.ProductChangeMessage{UpdateTimestamp: base.Unix() + sec, UpdateProductInfoList: products}
+}
+
+func chatAt(tracker *ShoppingTracker, secs ...int64) {
+	for _, sec := range secs {
+		tracker.Chat(base.Add(time.Duration(sec) * time.Second))
+	}
+}
+
+func TestShoppingExplanations(t *testing.T) {
+	tracker := NewShoppingTracker()
+	chatAt(tracker, -30, 50)
+	events := tracker.ProductChange(productChange(60, &douyin.ProductInfo{PromotionId: 1, ExplainType: 1}))
+	if len(events) != 2 || events[1].Kind != ProductEventExplain || events[1].PromotionId != 1 {
+		t.Fatalf("开始讲解事件错误: %+v", events)
+	}
+	chatAt(tracker, 61, 62, 62)
+	// 开始讲解另一个商品时结束当前讲解
+	events = tracker.ProductChange(productChange(90, &douyin.ProductInfo{PromotionId: 2, ExplainType: 1}))
+	if len(events) != 3 || events[1].Kind != ProductEventExplainEnd || events[1].PromotionId != 1 || events[2].PromotionId != 2 {
+		t.Fatalf("切换讲解事件错误: %+v", events)
+	}
+	chatAt(tracker, 100)
+	// 讲解类型为 0 表示结束讲解
+	events = tracker.ProductChange(productChange(120, &douyin.ProductInfo{PromotionId: 2}))
+	if len(events) != 2 || events[1].Kind != ProductEventExplainEnd || !events[1].Time.Equal(base.Add(120*time.Second)) {
+		t.Fatalf("结束讲解事件错误: %+v", events)
+	}
+
+	explanations := tracker.Explanations()
+	if len(explanations) != 2 {
+		t.Fatalf("期望 2 次讲解，得到 %+v", explanations)
+	}
+	first, second := explanations[0], explanations[1]
+	if !first.Start.Equal(base.Add(60*time.Second)) || !first.End.Equal(base.Add(90*time.Second)) {
+		t.Fatalf("第一次讲解时间错误: %+v", first)
+	}
+	// 讲解期间 3 条弹幕，开始前一分钟内只有第 50 秒的 1 条
+	if first.Chats != 3 || first.ChatsBefore != 1 {
+		t.Fatalf("第一次讲解弹幕量错误: %+v", first)
+	}
+	if second.Chats != 1 || second.ChatsBefore != 4 {
+		t.Fatalf("第二次讲解弹幕量错误: %+v", second)
+	}
+}
+
+func TestShoppingFinishUsesMessageTime(t *testing.T) {
+	tracker := NewShoppingTracker()
+	tracker.ProductChange(productChange(0, &douyin.ProductInfo{PromotionId: 1, ExplainType: 1}))
+	chatAt(tracker, 10, 30)
+	if explanations := tracker.Explanations(); !explanations[0].End.IsZero() || explanations[0].Chats != 1 {
+		t.Fatalf("进行中的讲解应统计到最近一条消息: %+v", explanations)
+	}
+	tracker.Finish()
+	explanation := tracker.Explanations()[0]
+	if !explanation.End.Equal(base.Add(30 * time.Second)) {
+		t.Fatalf("结束时间应为最近一条消息的时间，得到 %v", explanation.End)
+	}
+}
+
+func TestShoppingTimestampUnits(t *testing.T) {
+	tracker := NewShoppingTracker()
+	// UpdateTimestamp 为毫秒时不应被当作秒
+	events := tracker.ProductChange(&douyin.ProductChangeMessage{UpdateTimestamp: base.UnixMilli()})
+	if !events[0].Time.Equal(base) {
+		t.Fatalf("毫秒时间戳解析错误: %v", events[0].Time)
+	}
+	// 有消息创建时间时以其为准，与弹幕时间保持一致
+	events = tracker.ProductChange(&douyin.ProductChangeMessage{
+		Common:          &douyin.Common{CreateTime: uint64(base.Add(time.Minute).UnixMilli())},
+		UpdateTimestamp: base.Unix(),
+	})
+	if !events[0].Time.Equal(base.Add(time.Minute)) {
+		t.Fatalf("应使用消息创建时间: %v", events[0].Time)
+	}
+}
+
+func TestShoppingChatsOutOfOrder(t *testing.T) {
+	tracker := NewShoppingTracker()
+	tracker.ProductChange(productChange(60, &douyin.ProductInfo{PromotionId: 1, ExplainType: 1}))
+	chatAt(tracker, 70, 20, 65, 59, 60)
+	tracker.ProductChange(productChange(80, &douyin.ProductInfo{PromotionId: 1}))
+	explanation := tracker.Explanations()[0]
+	if explanation.Chats != 3 || explanation.ChatsBefore != 2 {
+		t.Fatalf("乱序弹幕统计错误: %+v", explanation)
+	}
+}
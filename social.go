@@ -0,0 +1,147 @@
+package douyinlive
+
+import (
+	"douyinlive/generated/douyin"
+	"sync"
+	"time"
+)
+
+// SocialMessage 的 action 取值
+const (
+	SocialActionFollow = 1
+	SocialActionShare  = 3
+)
+
+// SocialEvent ParseSocialMessage 解析出的事件，只有 *FollowEvent 与 *ShareEvent 两种
+type SocialEvent interface {
+	socialEvent()
+}
+
+func (*FollowEvent) socialEvent() {}
+func (*ShareEvent) socialEvent()  {}
+
+// EventUser 事件中的用户信息
+type EventUser struct {
+	UserId   uint64 `json:"user_id"`
+	SecUid   string `json:"sec_uid"`
+	NickName string `json:"nick_name"`
+}
+
+// FollowEvent 观众关注主播事件
+type FollowEvent struct {
+	MsgId       uint64    `json:"msg_id"`
+	User        EventUser `json:"user"`
+	FollowCount uint64    `json:"follow_count"` // 关注后主播的粉丝数
+	Time        time.Time `json:"time"`
+}
+
+// ShareEvent 观众分享直播间事件
+type ShareEvent struct {
+	MsgId       uint64    `json:"msg_id"`
+	User        EventUser `json:"user"`
+	ShareType   uint64    `json:"share_type"`
+	ShareTarget string    `json:"share_target"`
+	Time        time.Time `json:"time"`
+}
+
+// newEventUser 从 User 中提取事件用户信息
+func newEventUser(user *douyin.User) EventUser {
+	if user == nil {
+		return EventUser{}
+	}
+	return EventUser{
+		UserId:   user.Id,
+		SecUid:   user.SecUid,
+		NickName: user.NickName,
+	}
+}
+
+// ParseSocialMessage 将 SocialMessage 转换为 *FollowEvent 或 *ShareEvent，未知 action 返回 nil
+func ParseSocialMessage(msg *douyin.SocialMessage) SocialEvent {
+	switch msg.Action {
+	case SocialActionFollow:
+		return &FollowEvent{
+			MsgId:       msg.Common.GetMsgId(),
+			User:        newEventUser(msg.User),
+			FollowCount: msg.FollowCount,
+			Time:        messageTime(msg.Common),
+		}
+	case SocialActionShare:
+		return &ShareEvent{
+			MsgId:       msg.Common.GetMsgId(),
+			User:        newEventUser(msg.User),
+			ShareType:   msg.ShareType,
+			ShareTarget: msg.ShareTarget,
+			Time:        messageTime(msg.Common),
+		}
+	}
+	return nil
+}
+
+// SocialSummary 本场直播的关注与分享统计
+type SocialSummary struct {
+	Follows        int            `json:"follows"`
+	FollowerGain   int64          `json:"follower_gain"`
+	Shares         int            `json:"shares"`
+	SharesByTarget map[string]int `json:"shares_by_target"`
+}
+
+// SocialTracker 统计单场直播的关注与分享
+type SocialTracker struct {
+	mu             sync.Mutex
+	follows        int
+	first          *FollowEvent // 带粉丝数的最早一次关注
+	last           *FollowEvent // 带粉丝数的最近一次关注
+	shares         int
+	sharesByTarget map[string]int
+}
+
+// NewSocialTracker 创建关注与分享统计器
+func NewSocialTracker() *SocialTracker {
+	return &SocialTracker{sharesByTarget: make(map[string]int)}
+}
+
+// Follow 记录关注事件，按消息时间取最早与最近一次的粉丝数计算涨粉
+//
+// 消息可能乱序到达，粉丝数缺失（为 0）的事件只计入关注次数
+func (t *SocialTracker) Follow(event *FollowEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.follows++
+	if event.FollowCount == 0 {
+		return
+	}
+	if t.first == nil || event.Time.Before(t.first.Time) {
+		t.first = event
+	}
+	if t.last == nil || !event.Time.Before(t.last.Time) {
+		t.last = event
+	}
+}
+
+// Share 记录分享事件
+func (t *SocialTracker) Share(event *ShareEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.shares++
+	t.sharesByTarget[event.ShareTarget]++
+}
+
+// Summary 返回本场关注与分享统计
+func (t *SocialTracker) Summary() SocialSummary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	summary := SocialSummary{
+		Follows:        t.follows,
+		Shares:         t.shares,
+		SharesByTarget: make(map[string]int, len(t.sharesByTarget)),
+	}
+	if t.first != nil {
+		// 最早一次关注中的粉丝数已包含该次关注
+		summary.FollowerGain = int64(t.last.FollowCount) - int64(t.first.FollowCount) + 1
+	}
+	for target, n := range t.sharesByTarget {
+		summary.SharesByTarget[target] = n
+	}
+	return summary
+}
@@ -0,0 +1,60 @@
+package douyinlive
+
+import (
+	"douyinlive/generated/douyin"
+	"testing"
+	"time"
+)
+
+func followAt(count uint64, sec int64) *FollowEvent {
+	return &FollowEvent{FollowCount: count, Time: time.Unix(1719159695+sec, 0)}
+}
+
+func TestSocialTrackerFollow(t *testing.T) {
+	tests := []struct {
+		name    string
+		events  []*FollowEvent
+		follows int
+		gain    int64
+	}{
+		{"按顺序", []*FollowEvent{followAt(101, 0), followAt(102, 1), followAt(105, 2)}, 3, 5},
+		{"乱序到达", []*FollowEvent{followAt(105, 2), followAt(101, 0), followAt(102, 1)}, 3, 5},
+		{"首个事件缺少粉丝数", []*FollowEvent{followAt(0, 0), followAt(201, 1), followAt(203, 2)}, 3, 3},
+		{"全部缺少粉丝数", []*FollowEvent{followAt(0, 0), followAt(0, 1)}, 2, 0},
+		{"期间有人取关", []*FollowEvent{followAt(101, 0), followAt(99, 5)}, 2, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewSocialTracker()
+			for _, e := range tt.events {
+				tracker.Follow(e)
+			}
+			summary := tracker.Summary()
+			if summary.Follows != tt.follows || summary.FollowerGain != tt.gain {
+				t.Fatalf("期望 %d 次关注涨粉 %d，得到 %+v", tt.follows, tt.gain, summary)
+			}
+		})
+	}
+}
+
+func TestParseSocialMessage(t *testing.T) {
+	common := &douyin.Common{MsgId: 7, CreateTime: 1719159695000}
+	follow, ok := ParseSocialMessage(&douyin.SocialMessage{Common: common, Action: SocialActionFollow, FollowCount: 42}).(*FollowEvent)
+	if !ok || follow.FollowCount != 42 || follow.MsgId != 7 || !follow.Time.Equal(time.UnixMilli(1719159695000)) {
+		t.Fatalf("关注事件解析错误: %+v", follow)
+	}
+	share, ok := ParseSocialMessage(&douyin.SocialMessage{Common: common, Action: SocialActionShare, ShareTarget: "wechat"}).(*ShareEvent)
+	if !ok || share.ShareTarget != "wechat" {
+		t.Fatalf("分享事件解析错误: %+v", share)
+	}
+	if event := ParseSocialMessage(&douyin.SocialMessage{Action: 2}); event != nil {
+		t.Fatalf("未知 action 应返回 nil，得到 %+v", event)
+	}
+
+	tracker := NewSocialTracker()
+	tracker.Share(share)
+	tracker.Share(share)
+	if summary := tracker.Summary(); summary.Shares != 2 || summary.SharesByTarget["wechat"] != 2 {
+		t.Fatalf("分享统计错误: %+v", summary)
+	}
+}
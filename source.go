@@ -0,0 +1,102 @@
+package douyinlive
+
+import (
+	"douyinlive/archive"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// FrameSource PushFrame 的来源，websocket 连接与录制回放都实现该接口
+type FrameSource interface {
+	// ReadFrame 读取一帧原始 PushFrame 数据，来源结束时返回错误
+	ReadFrame() ([]byte, error)
+	// WriteFrame 发送 ack 等数据帧
+	WriteFrame(data []byte) error
+	Close() error
+}
+
+// wsSource 抖音 websocket 连接
+type wsSource struct {
+	conn *websocket.Conn
+}
+
+func (s *wsSource) ReadFrame() ([]byte, error) {
+	_, message, err := s.conn.ReadMessage()
+	return message, err
+}
+
+func (s *wsSource) WriteFrame(data []byte) error {
+	return s.conn.WriteMessage(websocket.BinaryMessage, data)
+}
+
+func (s *wsSource) Close() error {
+	return s.conn.Close()
+}
+
+// ReplayOptions 回放参数
+type ReplayOptions struct {
+	Paced bool    // 按录制时的时间间隔回放，false 时尽可能快
+	Speed float64 // 回放倍速，Paced 为 true 时生效，<= 0 视为 1
+}
+
+// ErrReplayClosed 回放来源已关闭
+var ErrReplayClosed = errors.New("回放已关闭")
+
+// ReplaySource 从帧归档中回放录制的帧，ack 会被丢弃
+type ReplaySource struct {
+	r         *archive.Reader
+	opts      ReplayOptions
+	start     time.Time     // 开始回放的时间
+	first     time.Time     // 第一帧的录制时间
+	done      chan struct{} // Close 时关闭，结束按节奏回放中的等待
+	closeOnce sync.Once
+}
+
+// NewReplaySource 创建回放来源，关闭时会一并关闭 r
+func NewReplaySource(r *archive.Reader, opts ReplayOptions) *ReplaySource {
+	if opts.Speed <= 0 {
+		opts.Speed = 1
+	}
+	return &ReplaySource{r: r, opts: opts, done: make(chan struct{})}
+}
+
+func (s *ReplaySource) ReadFrame() ([]byte, error) {
+	frame, err := s.r.Next()
+	if err != nil {
+		return nil, err
+	}
+	if !s.opts.Paced {
+		return frame.Data, nil
+	}
+	if s.first.IsZero() {
+		s.first = frame.Time
+		s.start = time.Now()
+		return frame.Data, nil
+	}
+	offset := time.Duration(float64(frame.Time.Sub(s.first)) / s.opts.Speed)
+	timer := time.NewTimer(time.Until(s.start.Add(offset)))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return frame.Data, nil
+	case <-s.done:
+		return nil, ErrReplayClosed
+	}
+}
+
+func (s *ReplaySource) WriteFrame(data []byte) error {
+	return nil
+}
+
+// Close 关闭归档，正在等待下一帧的 ReadFrame 会立即返回 ErrReplayClosed
+func (s *ReplaySource) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		err = s.r.Close()
+	})
+	return err
+}
@@ -0,0 +1,189 @@
+package douyinlive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"douyinlive/archive"
+	"douyinlive/generated/douyin"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// chatFrame 一条 gzip 压缩、包含一条弹幕的 PushFrame
+func chatFrame(t *testing.T, content string) []byte {
+	t.Helper()
+	chat, _ := proto.Marshal(&douyin.ChatMessage{User: &douyin.User{Id: 1}, Content: content})
+	response, _ := proto.Marshal(&douyin.Response{
+		MessagesList: []*douyin.Message{{Method: WebcastChatMessage, Payload: chat}},
+		NeedAck:      true,
+	})
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	_, _ = w.Write(response)
+	_ = w.Close()
+	frame, err := proto.Marshal(&douyin.PushFrame{
+		PayloadType: "msg",
+		HeadersList: []*douyin.HeadersList{{Key: "compress_type", Value: "gzip"}},
+		Payload:     compressed.Bytes(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return frame
+}
+
+// recordArchive 录制三帧，间隔 200ms
+func recordArchive(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "room.frames")
+	header := archive.Header{WebRid: "644826113301", RoomId: "7383731312643626035", StartTime: base}
+	w, err := archive.Create(path, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, content := range []string{"一", "二", "三"} {
+		if err := w.WriteFrame(base.Add(time.Duration(i)*200*time.Millisecond), chatFrame(t, content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// replay 回放归档，返回收到的弹幕与耗时
+func replay(t *testing.T, path string, opts ReplayOptions) ([]string, time.Duration) {
+	t.Helper()
+	r, err := archive.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := NewReplayLive(r.Header)
+	var contents []string
+	d.Subscribe(func(message *Event) {
+		if message.Method != WebcastChatMessage {
+			return
+		}
+		if message.WebRid != "644826113301" || message.Session != 7 {
+			t.Errorf("消息未标记直播间与场次: %s %d", message.WebRid, message.Session)
+		}
+		chat, ok := message.Decoded.(*douyin.ChatMessage)
+		if !ok {
+			t.Fatalf("弹幕未解码: %v", message.DecodeErr)
+		}
+		contents = append(contents, chat.Content)
+	})
+	start := time.Now()
+	d.StartSource(context.Background(), NewReplaySource(r, opts), 7)
+	return contents, time.Since(start)
+}
+
+func TestReplaySource(t *testing.T) {
+	path := recordArchive(t)
+
+	contents, elapsed := replay(t, path, ReplayOptions{})
+	if len(contents) != 3 || contents[0] != "一" || contents[2] != "三" {
+		t.Fatalf("回放的弹幕错误: %v", contents)
+	}
+	if elapsed >= 400*time.Millisecond {
+		t.Fatalf("不按节奏回放时不应等待，耗时 %v", elapsed)
+	}
+
+	// 录制跨度 400ms，4 倍速约 100ms
+	contents, elapsed = replay(t, path, ReplayOptions{Paced: true, Speed: 4})
+	if len(contents) != 3 {
+		t.Fatalf("回放的弹幕错误: %v", contents)
+	}
+	if elapsed < 90*time.Millisecond || elapsed >= 400*time.Millisecond {
+		t.Fatalf("4 倍速回放耗时应约为 100ms，得到 %v", elapsed)
+	}
+
+	// 按原速回放约 400ms
+	if _, elapsed = replay(t, path, ReplayOptions{Paced: true}); elapsed < 390*time.Millisecond {
+		t.Fatalf("原速回放耗时应约为 400ms，得到 %v", elapsed)
+	}
+}
+
+func TestReplaySourceCancel(t *testing.T) {
+	r, err := archive.Open(recordArchive(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := NewReplayLive(r.Header)
+	ctx, cancel := context.WithCancel(context.Background())
+	var received int
+	d.Subscribe(func(message *Event) {
+		if message.Method == WebcastChatMessage {
+			received++
+			cancel()
+		}
+	})
+	// 0.01 倍速下第二帧需要等待 20s，取消后应在读取下一帧前退出
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.StartSource(ctx, NewReplaySource(r, ReplayOptions{Paced: true, Speed: 0.01}), 0)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("取消后回放未退出")
+	}
+	if received != 1 {
+		t.Fatalf("取消后不应继续处理，收到 %d 条", received)
+	}
+}
+
+func TestReplaySourceCancelDuringWait(t *testing.T) {
+	r, err := archive.Open(recordArchive(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := NewReplayLive(r.Header)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	first := make(chan struct{}, 1)
+	d.Subscribe(func(message *Event) {
+		if message.Method == WebcastChatMessage {
+			first <- struct{}{}
+		}
+	})
+	// 0.01 倍速下第二帧需要等待 20s，等待期间取消应立即退出
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.StartSource(ctx, NewReplaySource(r, ReplayOptions{Paced: true, Speed: 0.01}), 0)
+	}()
+	<-first
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("等待下一帧时取消，回放未退出")
+	}
+}
+
+func TestReplaySourceCloseDuringWait(t *testing.T) {
+	r, err := archive.Open(recordArchive(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := NewReplaySource(r, ReplayOptions{Paced: true, Speed: 0.01})
+	if _, err := source.ReadFrame(); err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(50*time.Millisecond, func() { _ = source.Close() })
+	start := time.Now()
+	if _, err := source.ReadFrame(); err != ErrReplayClosed {
+		t.Fatalf("关闭后应返回 ErrReplayClosed，得到 %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("关闭后仍在等待，耗时 %v", elapsed)
+	}
+}
@@ -2,35 +2,40 @@ package douyinlive
 
 import (
 	"compress/gzip"
+	"douyinlive/archive"
 	"douyinlive/generated/douyin"
 	"github.com/gorilla/websocket"
 	"github.com/imroc/req/v3"
+	"google.golang.org/protobuf/proto"
 	"net/http"
 	"sync"
+	"sync/atomic"
 )
 
-const (
-	WebcastChatMessage        = "WebcastChatMessage"
-	WebcastGiftMessage        = "WebcastGiftMessage"
-	WebcastLikeMessage        = "WebcastLikeMessage"
-	WebcastMemberMessage      = "WebcastMemberMessage"
-	WebcastSocialMessage      = "WebcastSocialMessage"
-	WebcastRoomUserSeqMessage = "WebcastRoomUserSeqMessage"
-	WebcastFansclubMessage    = "WebcastFansclubMessage"
-	WebcastControlMessage     = "WebcastControlMessage"
-	WebcastEmojiChatMessage   = "WebcastEmojiChatMessage"
-	WebcastRoomStatsMessage   = "WebcastRoomStatsMessage"
-	WebcastRoomMessage        = "WebcastRoomMessage"
-	WebcastRoomRankMessage    = "WebcastRoomRankMessage"
+//go:generate go run ./cmd/msggen
 
+const (
 	Default = "Default"
 )
 
-type EventHandler func(eventData *douyin.Message)
+// Event 分发给订阅者的消息，直播间与场次由 DouyinLive 填充，不写入生成的 douyin.Message
+type Event struct {
+	*douyin.Message
+	WebRid  WebRid
+	RoomID  RoomID
+	Session SessionID
+	// Decoded 已注册消息解码后的结果，订阅者之间共享，不应修改；未注册或解码失败时为 nil
+	Decoded proto.Message
+	// DecodeErr 已注册消息解码失败的原因
+	DecodeErr error
+}
+
+type EventHandler func(event *Event)
 type DouyinLive struct {
 	ttwid         string
-	roomid        string
-	liveid        string
+	roomID        RoomID
+	webRid        WebRid
+	session       SessionID
 	liveurl       string
 	userAgent     string
 	c             *req.Client
@@ -42,4 +47,13 @@ type DouyinLive struct {
 	wssurl        string
 	pushid        string
 	isLiveClosed  bool
+	stateMu       sync.RWMutex // 保护 session 与各统计器，处理协程写入时接口可能并发读取
+	viewers       *ViewerTracker
+	social        *SocialTracker
+	shopping      *ShoppingTracker
+	recordMu      sync.Mutex
+	recorder      *archive.Writer
+	startedAt     atomic.Int64 // 开始处理的时间（UnixNano）
+	frames        atomic.Int64
+	messages      atomic.Int64
 }
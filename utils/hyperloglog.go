@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"errors"
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+// hllPrecision 寄存器位数，2^14 个寄存器，标准误差约 0.8%
+const hllPrecision = 14
+
+const hllRegisters = 1 << hllPrecision
+
+// HyperLogLog 基数估计器，用于大直播间的观众去重计数
+type HyperLogLog struct {
+	registers []uint8
+}
+
+// NewHyperLogLog 创建一个空的 HyperLogLog
+func NewHyperLogLog() *HyperLogLog {
+	return &HyperLogLog{registers: make([]uint8, hllRegisters)}
+}
+
+// HyperLogLogFromBytes 从 Bytes 导出的数据恢复 HyperLogLog
+func HyperLogLogFromBytes(data []byte) (*HyperLogLog, error) {
+	if len(data) != hllRegisters {
+		return nil, errors.New("HyperLogLog 数据长度不正确")
+	}
+	h := NewHyperLogLog()
+	copy(h.registers, data)
+	return h, nil
+}
+
+// hash64 计算字符串的 64 位哈希，FNV 之后再做一次 fmix64 混淆，保证高位分布均匀
+func hash64(s string) uint64 {
+	f := fnv.New64a()
+	_, _ = f.Write([]byte(s))
+	x := f.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// Add 添加一个元素
+func (h *HyperLogLog) Add(s string) {
+	x := hash64(s)
+	idx := x >> (64 - hllPrecision)
+	rank := uint8(bits.LeadingZeros64(x<<hllPrecision|1<<(hllPrecision-1)) + 1)
+	if rank > h.registers[idx] {
+		h.registers[idx] = rank
+	}
+}
+
+// Merge 将另一个 HyperLogLog 合并进来
+func (h *HyperLogLog) Merge(other *HyperLogLog) {
+	for i, r := range other.registers {
+		if r > h.registers[i] {
+			h.registers[i] = r
+		}
+	}
+}
+
+// Clone 复制一份 HyperLogLog
+func (h *HyperLogLog) Clone() *HyperLogLog {
+	c := NewHyperLogLog()
+	copy(c.registers, h.registers)
+	return c
+}
+
+// Count 返回估计的基数
+func (h *HyperLogLog) Count() uint64 {
+	m := float64(hllRegisters)
+	sum := 0.0
+	zeros := 0
+	for _, r := range h.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+	alpha := 0.7213 / (1 + 1.079/m)
+	estimate := alpha * m * m / sum
+	// 小基数时使用线性计数修正
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+// Bytes 导出寄存器数据，用于持久化
+func (h *HyperLogLog) Bytes() []byte {
+	data := make([]byte, len(h.registers))
+	copy(data, h.registers)
+	return data
+}
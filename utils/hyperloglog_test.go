@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestHyperLogLogCount(t *testing.T) {
+	h := NewHyperLogLog()
+	for i := 0; i < 200000; i++ {
+		h.Add(strconv.Itoa(i))
+		h.Add(strconv.Itoa(i)) // 重复元素不应影响计数
+	}
+	count := float64(h.Count())
+	if count < 200000*0.97 || count > 200000*1.03 {
+		t.Fatalf("估计值偏差过大: %v", count)
+	}
+
+	restored, err := HyperLogLogFromBytes(h.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Count() != h.Count() {
+		t.Fatalf("恢复后计数不一致: %d != %d", restored.Count(), h.Count())
+	}
+}
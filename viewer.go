@@ -0,0 +1,325 @@
+package douyinlive
+
+import (
+	"douyinlive/generated/douyin"
+	"douyinlive/model"
+	"douyinlive/utils"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AudienceExactLimit 历史观众数量超过该值后，精确集合转换为 HyperLogLog
+const AudienceExactLimit = 100000
+
+const (
+	audienceModeExact = "exact"
+	audienceModeHLL   = "hll"
+)
+
+// Viewer 单个观众在本场直播中的记录
+type Viewer struct {
+	UserId    uint64    `json:"user_id"`
+	SecUid    string    `json:"sec_uid"`
+	NickName  string    `json:"nick_name"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Enters    int       `json:"enters"`
+	EnterType int64     `json:"enter_type"`
+	Returning bool      `json:"returning"` // 往期直播中出现过，仅精确模式下可判定
+}
+
+// Dwell 近似停留时长：首次出现到最后一次活跃的间隔，按消息时间计算
+func (v *Viewer) Dwell() time.Duration {
+	return v.LastSeen.Sub(v.FirstSeen)
+}
+
+// messageTime 消息创建时间，缺失时使用当前时间
+func messageTime(common *douyin.Common) time.Time {
+	if common == nil || common.CreateTime == 0 {
+		return time.Now()
+	}
+	return time.UnixMilli(int64(common.CreateTime))
+}
+
+// ViewerSummary 本场直播观众统计
+type ViewerSummary struct {
+	UniqueViewers    int           `json:"unique_viewers"`
+	ReturningViewers int           `json:"returning_viewers"`
+	MemberCount      int64         `json:"member_count"`
+	PeakMemberCount  int64         `json:"peak_member_count"`
+	AvgDwell         time.Duration `json:"avg_dwell"`
+}
+
+// viewerKey 观众唯一标识，优先使用用户 ID，其次 secUid
+func viewerKey(user *douyin.User) string {
+	if user == nil {
+		return ""
+	}
+	if user.Id != 0 {
+		return strconv.FormatUint(user.Id, 10)
+	}
+	if user.SecUid != "" {
+		return user.SecUid
+	}
+	return user.IdStr
+}
+
+// AnchorAudience 主播跨场次的历史观众集合，小直播间使用精确集合，大直播间使用 HyperLogLog
+type AnchorAudience struct {
+	AnchorId string
+	Sessions int
+	exact    map[string]struct{}
+	hll      *utils.HyperLogLog
+}
+
+// NewAnchorAudience 创建一个空的历史观众集合
+func NewAnchorAudience(anchorId string) *AnchorAudience {
+	return &AnchorAudience{
+		AnchorId: anchorId,
+		exact:    make(map[string]struct{}),
+	}
+}
+
+// LoadAnchorAudience 从数据库加载主播历史观众集合
+func LoadAnchorAudience(anchorId string) (*AnchorAudience, error) {
+	record, err := model.GetAnchorAudience(anchorId)
+	if err != nil {
+		return nil, err
+	}
+	a := NewAnchorAudience(anchorId)
+	if record == nil {
+		return a, nil
+	}
+	a.Sessions = record.Sessions
+	if record.Mode == audienceModeHLL {
+		a.exact = nil
+		a.hll, err = utils.HyperLogLogFromBytes(record.Data)
+		return a, err
+	}
+	var keys []string
+	if len(record.Data) > 0 {
+		if err := json.Unmarshal(record.Data, &keys); err != nil {
+			return nil, err
+		}
+	}
+	for _, key := range keys {
+		a.exact[key] = struct{}{}
+	}
+	return a, nil
+}
+
+// Exact 是否为精确模式
+func (a *AnchorAudience) Exact() bool {
+	return a.hll == nil
+}
+
+// Contains 判断观众是否在往期出现过，HyperLogLog 模式下无法判定，ok 返回 false
+func (a *AnchorAudience) Contains(key string) (seen bool, ok bool) {
+	if !a.Exact() {
+		return false, false
+	}
+	_, seen = a.exact[key]
+	return seen, true
+}
+
+// Count 历史观众数量
+func (a *AnchorAudience) Count() uint64 {
+	if a.Exact() {
+		return uint64(len(a.exact))
+	}
+	return a.hll.Count()
+}
+
+// Overlap 估算本场观众中有多少在往期出现过
+func (a *AnchorAudience) Overlap(keys []string) int {
+	if a.Exact() {
+		n := 0
+		for _, key := range keys {
+			if _, ok := a.exact[key]; ok {
+				n++
+			}
+		}
+		return n
+	}
+	session := utils.NewHyperLogLog()
+	for _, key := range keys {
+		session.Add(key)
+	}
+	union := a.hll.Clone()
+	union.Merge(session)
+	overlap := int(a.hll.Count()) + len(keys) - int(union.Count())
+	if overlap < 0 {
+		return 0
+	}
+	return overlap
+}
+
+// Merge 将本场观众并入历史集合，超过 AudienceExactLimit 时转换为 HyperLogLog
+func (a *AnchorAudience) Merge(keys []string) {
+	a.Sessions++
+	if a.Exact() {
+		for _, key := range keys {
+			a.exact[key] = struct{}{}
+		}
+		if len(a.exact) <= AudienceExactLimit {
+			return
+		}
+		a.hll = utils.NewHyperLogLog()
+		for key := range a.exact {
+			a.hll.Add(key)
+		}
+		a.exact = nil
+		return
+	}
+	for _, key := range keys {
+		a.hll.Add(key)
+	}
+}
+
+// Save 持久化历史观众集合
+func (a *AnchorAudience) Save() error {
+	record := &model.AnchorAudience{
+		AnchorId: a.AnchorId,
+		Sessions: a.Sessions,
+	}
+	if a.Exact() {
+		keys := make([]string, 0, len(a.exact))
+		for key := range a.exact {
+			keys = append(keys, key)
+		}
+		data, err := json.Marshal(keys)
+		if err != nil {
+			return err
+		}
+		record.Mode = audienceModeExact
+		record.Data = data
+	} else {
+		record.Mode = audienceModeHLL
+		record.Data = a.hll.Bytes()
+	}
+	return model.SaveAnchorAudience(record)
+}
+
+// ViewerTracker 统计单场直播的观众进出情况
+type ViewerTracker struct {
+	mu              sync.Mutex
+	viewers         map[string]*Viewer
+	audience        *AnchorAudience
+	memberCount     int64
+	peakMemberCount int64
+}
+
+// NewViewerTracker 创建观众统计器，audience 为 nil 时不做回访识别
+func NewViewerTracker(audience *AnchorAudience) *ViewerTracker {
+	return &ViewerTracker{
+		viewers:  make(map[string]*Viewer),
+		audience: audience,
+	}
+}
+
+// touch 记录观众在消息时间 at 的活跃，消息乱序时同样取最早与最晚的时间，返回观众记录
+func (t *ViewerTracker) touch(user *douyin.User, at time.Time) *Viewer {
+	key := viewerKey(user)
+	if key == "" {
+		return nil
+	}
+	v, ok := t.viewers[key]
+	if !ok {
+		v = &Viewer{
+			UserId:    user.Id,
+			SecUid:    user.SecUid,
+			NickName:  user.NickName,
+			FirstSeen: at,
+		}
+		if t.audience != nil {
+			v.Returning, _ = t.audience.Contains(key)
+		}
+		t.viewers[key] = v
+	}
+	if at.Before(v.FirstSeen) {
+		v.FirstSeen = at
+	}
+	if at.After(v.LastSeen) {
+		v.LastSeen = at
+	}
+	return v
+}
+
+// Enter 处理进入直播间消息
+func (t *ViewerTracker) Enter(msg *douyin.MemberMessage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if msg.MemberCount > 0 {
+		t.memberCount = msg.MemberCount
+		if msg.MemberCount > t.peakMemberCount {
+			t.peakMemberCount = msg.MemberCount
+		}
+	}
+	v := t.touch(msg.User, messageTime(msg.Common))
+	if v == nil {
+		return
+	}
+	v.Enters++
+	v.EnterType = msg.EnterType
+}
+
+// Touch 记录观众的其他互动（聊天、礼物、点赞等），at 为消息时间，用于估算停留时长
+func (t *ViewerTracker) Touch(user *douyin.User, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.touch(user, at)
+}
+
+// Viewers 返回本场观众记录的快照
+func (t *ViewerTracker) Viewers() []Viewer {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	viewers := make([]Viewer, 0, len(t.viewers))
+	for _, v := range t.viewers {
+		viewers = append(viewers, *v)
+	}
+	return viewers
+}
+
+// keys 本场观众标识列表，调用方需持有锁
+func (t *ViewerTracker) keys() []string {
+	keys := make([]string, 0, len(t.viewers))
+	for key := range t.viewers {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Summary 返回本场观众统计
+func (t *ViewerTracker) Summary() ViewerSummary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	summary := ViewerSummary{
+		UniqueViewers:   len(t.viewers),
+		MemberCount:     t.memberCount,
+		PeakMemberCount: t.peakMemberCount,
+	}
+	if len(t.viewers) == 0 {
+		return summary
+	}
+	var dwell time.Duration
+	for _, v := range t.viewers {
+		dwell += v.Dwell()
+	}
+	summary.AvgDwell = dwell / time.Duration(len(t.viewers))
+	if t.audience != nil {
+		summary.ReturningViewers = t.audience.Overlap(t.keys())
+	}
+	return summary
+}
+
+// Finish 本场结束，将观众并入主播历史集合
+func (t *ViewerTracker) Finish() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.audience != nil {
+		t.audience.Merge(t.keys())
+	}
+}
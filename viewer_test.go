@@ -0,0 +1,101 @@
+package douyinlive
+
+import (
+	"douyinlive/generated/douyin"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// base 测试使用的直播开始时间，远早于当前时间，确保统计不依赖 time.Now
+var base = time.Unix(1719159695, 0)
+
+func memberAt(id uint64, sec int64, memberCount int64) *douyin.MemberMessage {
+	return &douyin.MemberMessage{
+		Common:      &douyin.Common{CreateTime: uint64(base.Add(time.Duration(sec) * time.Second).UnixMilli())},
+		User:        &douyin.User{Id: id},
+		MemberCount: memberCount,
+	}
+}
+
+func TestViewerDwell(t *testing.T) {
+	tracker := NewViewerTracker(nil)
+	tracker.Enter(memberAt(1, 0, 10))
+	tracker.Touch(&douyin.User{Id: 1}, base.Add(60*time.Second))
+	// 乱序到达的较早消息不缩短停留时长
+	tracker.Touch(&douyin.User{Id: 1}, base.Add(20*time.Second))
+	tracker.Enter(memberAt(2, 30, 8))
+	tracker.Touch(&douyin.User{Id: 2}, base.Add(50*time.Second))
+
+	viewers := make(map[uint64]Viewer)
+	for _, v := range tracker.Viewers() {
+		viewers[v.UserId] = v
+	}
+	first := viewers[1]
+	if d := first.Dwell(); d != time.Minute {
+		t.Fatalf("观众 1 停留时长应为 1 分钟，得到 %v", d)
+	}
+	if !viewers[2].FirstSeen.Equal(base.Add(30 * time.Second)) {
+		t.Fatalf("首次出现时间应取消息时间，得到 %v", viewers[2].FirstSeen)
+	}
+	summary := tracker.Summary()
+	if summary.UniqueViewers != 2 || summary.AvgDwell != 40*time.Second || summary.MemberCount != 8 || summary.PeakMemberCount != 10 {
+		t.Fatalf("观众统计错误: %+v", summary)
+	}
+}
+
+func TestViewerReturning(t *testing.T) {
+	audience := NewAnchorAudience("anchor")
+	audience.Merge([]string{"1", "MS4wLjABAAAA"})
+	tracker := NewViewerTracker(audience)
+	tracker.Enter(memberAt(1, 0, 0))
+	tracker.Touch(&douyin.User{SecUid: "MS4wLjABAAAA"}, base)
+	tracker.Touch(&douyin.User{Id: 3}, base)
+
+	returning := 0
+	for _, v := range tracker.Viewers() {
+		if v.Returning {
+			returning++
+		} else if v.UserId != 3 {
+			t.Fatalf("往期观众未识别: %+v", v)
+		}
+	}
+	if returning != 2 || tracker.Summary().ReturningViewers != 2 {
+		t.Fatalf("期望 2 名回访观众，得到 %d %+v", returning, tracker.Summary())
+	}
+
+	tracker.Finish()
+	if audience.Sessions != 2 || audience.Count() != 3 {
+		t.Fatalf("本场观众应并入历史集合: %d 场 %d 人", audience.Sessions, audience.Count())
+	}
+}
+
+func TestAnchorAudienceSwitchToHLL(t *testing.T) {
+	keys := make([]string, AudienceExactLimit)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	audience := NewAnchorAudience("anchor")
+	audience.Merge(keys)
+	if !audience.Exact() || audience.Count() != AudienceExactLimit {
+		t.Fatalf("达到上限前应保持精确模式: %v %d", audience.Exact(), audience.Count())
+	}
+	if seen, ok := audience.Contains("42"); !seen || !ok {
+		t.Fatal("精确模式下应能判定回访")
+	}
+
+	audience.Merge([]string{strconv.Itoa(AudienceExactLimit)})
+	if audience.Exact() {
+		t.Fatal("超过上限后应转换为 HyperLogLog")
+	}
+	if _, ok := audience.Contains("42"); ok {
+		t.Fatal("HyperLogLog 模式下无法判定回访")
+	}
+	// HyperLogLog 的误差在 2% 以内
+	if count := float64(audience.Count()); count < AudienceExactLimit*0.98 || count > AudienceExactLimit*1.02 {
+		t.Fatalf("HyperLogLog 估算偏差过大: %v", count)
+	}
+	if overlap := audience.Overlap(keys[:1000]); overlap < 900 || overlap > 1100 {
+		t.Fatalf("重合估算偏差过大: %d", overlap)
+	}
+}
@@ -0,0 +1,132 @@
+// Package watchlist 持久化需要自动开播抓取的主播列表
+package watchlist
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrExists 主播已在关注列表中
+	ErrExists = errors.New("主播已在关注列表中")
+	// ErrMissingKey 缺少 web_rid 或 sec_uid
+	ErrMissingKey = errors.New("缺少 web_rid 或 sec_uid")
+)
+
+// Entry 关注的主播，WebRid 与 SecUid 至少有一个
+type Entry struct {
+	WebRid  string    `json:"web_rid,omitempty"`
+	SecUid  string    `json:"sec_uid,omitempty"`
+	Record  bool      `json:"record"` // 开播后是否录制原始帧
+	AddedAt time.Time `json:"added_at"`
+}
+
+// Key 条目的唯一标识，优先使用 web_rid
+func (e Entry) Key() string {
+	if e.WebRid != "" {
+		return e.WebRid
+	}
+	return e.SecUid
+}
+
+// Store 关注列表，每次修改后写入 JSON 文件，可并发调用
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	entries []Entry
+}
+
+// Open 读取关注列表，文件不存在时返回空列表
+func Open(path string) (*Store, error) {
+	s := &Store{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.entries); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// List 返回关注列表的副本
+func (s *Store) List() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Entry(nil), s.entries...)
+}
+
+// Add 添加主播并保存
+func (s *Store) Add(entry Entry) error {
+	if entry.Key() == "" {
+		return ErrMissingKey
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.entries {
+		if e.Key() == entry.Key() || (entry.SecUid != "" && e.SecUid == entry.SecUid) {
+			return ErrExists
+		}
+	}
+	if entry.AddedAt.IsZero() {
+		entry.AddedAt = time.Now()
+	}
+	s.entries = append(s.entries, entry)
+	return s.save()
+}
+
+// Remove 按 web_rid 或 sec_uid 删除主播并保存，不存在时返回 false
+//
+// key 为空时返回 ErrMissingKey，否则会误删只有 sec_uid 的条目
+func (s *Store) Remove(key string) (bool, error) {
+	if key == "" {
+		return false, ErrMissingKey
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, e := range s.entries {
+		if e.WebRid == key || e.SecUid == key {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			return true, s.save()
+		}
+	}
+	return false, nil
+}
+
+// Update 更新主播信息并保存，例如通过 sec_uid 关注的主播开播后补全 web_rid
+func (s *Store) Update(key string, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, e := range s.entries {
+		if e.Key() == key {
+			s.entries[i] = entry
+			return s.save()
+		}
+	}
+	return nil
+}
+
+// save 先写临时文件再重命名，避免进程退出时写坏文件
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(s.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
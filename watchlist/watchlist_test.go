@@ -0,0 +1,54 @@
+package watchlist
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watchlist.json")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(Entry{WebRid: "644826113301", Record: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(Entry{SecUid: "MS4wLjABAAAA"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Add(Entry{WebRid: "644826113301"}); err != ErrExists {
+		t.Fatalf("期望 ErrExists，得到 %v", err)
+	}
+	if err := s.Update("MS4wLjABAAAA", Entry{WebRid: "23020419981", SecUid: "MS4wLjABAAAA"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 重新打开后内容不变
+	s, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := s.List()
+	if len(entries) != 2 || !entries[0].Record || entries[1].WebRid != "23020419981" {
+		t.Fatalf("关注列表错误: %+v", entries)
+	}
+	if ok, err := s.Remove("MS4wLjABAAAA"); !ok || err != nil {
+		t.Fatalf("删除失败: %v %v", ok, err)
+	}
+	if ok, _ := s.Remove("1"); ok {
+		t.Fatal("不存在的主播不应删除成功")
+	}
+	if err := s.Add(Entry{SecUid: "MS4wLjABAAAB"}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := s.Remove(""); ok || err != ErrMissingKey {
+		t.Fatalf("空 key 应返回 ErrMissingKey: %v %v", ok, err)
+	}
+	if ok, err := s.Remove("MS4wLjABAAAB"); !ok || err != nil {
+		t.Fatalf("删除失败: %v %v", ok, err)
+	}
+	if len(s.List()) != 1 {
+		t.Fatalf("关注列表错误: %+v", s.List())
+	}
+}